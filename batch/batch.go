@@ -0,0 +1,152 @@
+// Package batch plans execution batches of classified statements.
+package batch
+
+import (
+	"fmt"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils/internal"
+	"github.com/apstndb/gsqlutils/stmtkind"
+)
+
+// Kind is a kind of Batch, it decides the API to execute the batch.
+type Kind int
+
+const (
+	KindInvalid Kind = iota
+
+	// KindDDL is a batch of DDL statements executed by UpdateDatabaseDdl API.
+	KindDDL
+
+	// KindDML is a batch of DML statements executed by ExecuteBatchDml API.
+	KindDML
+
+	// KindSingle is a single statement executed by ExecuteSql API,
+	// e.g. query, CALL, GRAPH, and DML with THEN RETURN.
+	KindSingle
+
+	// KindClientSide is a single client-side statement handled by the caller, e.g. `START BATCH DDL`.
+	KindClientSide
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindDDL:
+		return "DDL"
+	case KindDML:
+		return "DML"
+	case KindSingle:
+		return "Single"
+	case KindClientSide:
+		return "ClientSide"
+	case KindInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(k))
+	}
+}
+
+// Batch is a group of statements executed at once.
+type Batch struct {
+	Kind       Kind
+	Statements []stmtkind.ClassifiedStatement
+}
+
+// Option is an option of Plan.
+type Option func(*planner)
+
+// WithMaxStatements limits the number of statements in a batch. Zero means no limit.
+func WithMaxStatements(n int) Option {
+	return func(p *planner) {
+		p.maxStatements = n
+	}
+}
+
+// WithMaxBytes limits the total bytes of statements in a batch. Zero means no limit.
+// A statement larger than the limit is planned as a batch of only the statement.
+func WithMaxBytes(n int) Option {
+	return func(p *planner) {
+		p.maxBytes = n
+	}
+}
+
+type planner struct {
+	maxStatements int
+	maxBytes      int
+}
+
+// Plan groups statements into ordered execution batches.
+// Consecutive DDL statements are grouped into a KindDDL batch, and consecutive DML statements are grouped into a KindDML batch.
+// Other statements are planned as KindSingle or KindClientSide batches.
+// Statements without any token, e.g. comments only, are skipped.
+// It returns an error if a statement has a detection error or an unsupported kind.
+func Plan(stmts []stmtkind.ClassifiedStatement, opts ...Option) ([]Batch, error) {
+	p := &planner{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	var batches []Batch
+	var bytes int
+	for _, stmt := range stmts {
+		if len(stmt.Tokens) == 0 {
+			continue
+		}
+
+		kind, err := batchKind(stmt)
+		if err != nil {
+			return nil, err
+		}
+
+		if last := len(batches) - 1; last >= 0 && internal.OneOf(kind, KindDDL, KindDML) && batches[last].Kind == kind && p.fits(batches[last], bytes, stmt) {
+			batches[last].Statements = append(batches[last].Statements, stmt)
+			bytes += len(stmt.Statement)
+			continue
+		}
+
+		batches = append(batches, Batch{Kind: kind, Statements: []stmtkind.ClassifiedStatement{stmt}})
+		bytes = len(stmt.Statement)
+	}
+	return batches, nil
+}
+
+// fits returns true if stmt can be appended to batch which has the total bytes.
+func (p *planner) fits(batch Batch, bytes int, stmt stmtkind.ClassifiedStatement) bool {
+	if p.maxStatements > 0 && len(batch.Statements) >= p.maxStatements {
+		return false
+	}
+	if p.maxBytes > 0 && bytes+len(stmt.Statement) > p.maxBytes {
+		return false
+	}
+	return true
+}
+
+func batchKind(stmt stmtkind.ClassifiedStatement) (Kind, error) {
+	if stmt.Err != nil {
+		return KindInvalid, fmt.Errorf("can't plan statement at %v: %w", stmt.Pos, stmt.Err)
+	}
+
+	switch {
+	case stmt.Kind.IsUpdateDDLCompatible():
+		return KindDDL, nil
+	case stmt.Kind.IsDML() && !hasThenReturn(stmt.Tokens):
+		return KindDML, nil
+	case stmt.Kind.IsExecuteSQLCompatible():
+		return KindSingle, nil
+	case stmt.Kind.IsClientSide():
+		return KindClientSide, nil
+	default:
+		return KindInvalid, fmt.Errorf("can't plan statement at %v: unsupported kind %v", stmt.Pos, stmt.Kind)
+	}
+}
+
+// hasThenReturn returns true if tokens contains THEN RETURN, DML with it can't be executed by ExecuteBatchDml API.
+func hasThenReturn(tokens []token.Token) bool {
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i-1].Kind == "THEN" && tokens[i].IsKeywordLike("RETURN") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package batch_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils/batch"
+	"github.com/apstndb/gsqlutils/stmtkind"
+)
+
+func TestPlan(t *testing.T) {
+	const input = `CREATE TABLE t (pk INT64) PRIMARY KEY (pk);
+CREATE INDEX t_idx ON t (pk);
+/* comment only */;
+INSERT INTO t (pk) VALUES (1);
+INSERT INTO t (pk) VALUES (2);
+UPDATE t SET pk = 3 WHERE pk = 2 THEN RETURN pk;
+DELETE FROM t WHERE TRUE;
+SELECT * FROM t;
+CALL cancel_query("1");
+DROP INDEX t_idx;
+DROP TABLE t;`
+
+	type batchResult struct {
+		Kind       batch.Kind
+		Statements []string
+	}
+
+	for _, tt := range []struct {
+		desc string
+		opts []batch.Option
+		want []batchResult
+	}{
+		{
+			desc: "no limit",
+			want: []batchResult{
+				{Kind: batch.KindDDL, Statements: []string{"CREATE TABLE t (pk INT64) PRIMARY KEY (pk)", "CREATE INDEX t_idx ON t (pk)"}},
+				{Kind: batch.KindDML, Statements: []string{"INSERT INTO t (pk) VALUES (1)", "INSERT INTO t (pk) VALUES (2)"}},
+				{Kind: batch.KindSingle, Statements: []string{"UPDATE t SET pk = 3 WHERE pk = 2 THEN RETURN pk"}},
+				{Kind: batch.KindDML, Statements: []string{"DELETE FROM t WHERE TRUE"}},
+				{Kind: batch.KindSingle, Statements: []string{"SELECT * FROM t"}},
+				{Kind: batch.KindSingle, Statements: []string{`CALL cancel_query("1")`}},
+				{Kind: batch.KindDDL, Statements: []string{"DROP INDEX t_idx", "DROP TABLE t"}},
+			},
+		},
+		{
+			desc: "max statements",
+			opts: []batch.Option{batch.WithMaxStatements(1)},
+			want: []batchResult{
+				{Kind: batch.KindDDL, Statements: []string{"CREATE TABLE t (pk INT64) PRIMARY KEY (pk)"}},
+				{Kind: batch.KindDDL, Statements: []string{"CREATE INDEX t_idx ON t (pk)"}},
+				{Kind: batch.KindDML, Statements: []string{"INSERT INTO t (pk) VALUES (1)"}},
+				{Kind: batch.KindDML, Statements: []string{"INSERT INTO t (pk) VALUES (2)"}},
+				{Kind: batch.KindSingle, Statements: []string{"UPDATE t SET pk = 3 WHERE pk = 2 THEN RETURN pk"}},
+				{Kind: batch.KindDML, Statements: []string{"DELETE FROM t WHERE TRUE"}},
+				{Kind: batch.KindSingle, Statements: []string{"SELECT * FROM t"}},
+				{Kind: batch.KindSingle, Statements: []string{`CALL cancel_query("1")`}},
+				{Kind: batch.KindDDL, Statements: []string{"DROP INDEX t_idx"}},
+				{Kind: batch.KindDDL, Statements: []string{"DROP TABLE t"}},
+			},
+		},
+		{
+			desc: "max bytes",
+			opts: []batch.Option{batch.WithMaxBytes(40)},
+			want: []batchResult{
+				{Kind: batch.KindDDL, Statements: []string{"CREATE TABLE t (pk INT64) PRIMARY KEY (pk)"}},
+				{Kind: batch.KindDDL, Statements: []string{"CREATE INDEX t_idx ON t (pk)"}},
+				{Kind: batch.KindDML, Statements: []string{"INSERT INTO t (pk) VALUES (1)"}},
+				{Kind: batch.KindDML, Statements: []string{"INSERT INTO t (pk) VALUES (2)"}},
+				{Kind: batch.KindSingle, Statements: []string{"UPDATE t SET pk = 3 WHERE pk = 2 THEN RETURN pk"}},
+				{Kind: batch.KindDML, Statements: []string{"DELETE FROM t WHERE TRUE"}},
+				{Kind: batch.KindSingle, Statements: []string{"SELECT * FROM t"}},
+				{Kind: batch.KindSingle, Statements: []string{`CALL cancel_query("1")`}},
+				{Kind: batch.KindDDL, Statements: []string{"DROP INDEX t_idx", "DROP TABLE t"}},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			stmts, err := stmtkind.ClassifyInput("", input)
+			if err != nil {
+				t.Fatalf("ClassifyInput() should success, but failed: %v", err)
+			}
+
+			batches, err := batch.Plan(stmts, tt.opts...)
+			if err != nil {
+				t.Fatalf("Plan() should success, but failed: %v", err)
+			}
+
+			var got []batchResult
+			for _, b := range batches {
+				var stmts []string
+				for _, stmt := range b.Statements {
+					stmts = append(stmts, stmt.Statement)
+				}
+				got = append(got, batchResult{Kind: b.Kind, Statements: stmts})
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in batches: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid statement", func(t *testing.T) {
+		stmts, err := stmtkind.ClassifyInput("", "SELECT 1; UNKNOWN STATEMENT;")
+		if err != nil {
+			t.Fatalf("ClassifyInput() should success, but failed: %v", err)
+		}
+
+		if _, err := batch.Plan(stmts); err == nil {
+			t.Error("should fail, but success")
+		}
+	})
+}
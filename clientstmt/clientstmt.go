@@ -0,0 +1,118 @@
+// Package clientstmt parses client-side statements, which are handled by drivers and CLIs instead of Spanner.
+// The default grammars follow the connection API conventions of Spanner JDBC and Go drivers.
+package clientstmt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+// Statement is a parsed client-side statement.
+type Statement struct {
+	// Name is the name of the grammar, e.g. "SHOW VARIABLE".
+	Name string
+
+	// Args are the named capture groups matched in the statement.
+	// Unmatched optional groups are not contained.
+	Args map[string]string
+}
+
+// Grammar is a grammar of a client-side statement.
+type Grammar struct {
+	Name string
+
+	// Pattern must match the whole statement without comments and the terminator.
+	// Named capture groups are stored in Statement.Args.
+	Pattern *regexp.Regexp
+}
+
+// NewGrammar compiles pattern as a case-insensitive Grammar which matches the whole statement.
+// Whitespaces around the statement are ignored.
+func NewGrammar(name, pattern string) (Grammar, error) {
+	re, err := regexp.Compile(`(?is)^\s*(?:` + pattern + `)\s*$`)
+	if err != nil {
+		return Grammar{}, fmt.Errorf("invalid pattern of %v, err: %w", name, err)
+	}
+	return Grammar{Name: name, Pattern: re}, nil
+}
+
+// MustGrammar is same as NewGrammar, but it panics if pattern is invalid.
+func MustGrammar(name, pattern string) Grammar {
+	g, err := NewGrammar(name, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Registry is an ordered set of grammars. The first matched grammar is used.
+type Registry struct {
+	grammars []Grammar
+}
+
+// NewRegistry returns a Registry of grammars.
+func NewRegistry(grammars ...Grammar) *Registry {
+	return &Registry{grammars: grammars}
+}
+
+// DefaultRegistry returns a new Registry of the default grammars, it can be extended by Register.
+func DefaultRegistry() *Registry {
+	return NewRegistry(defaultGrammars...)
+}
+
+// Register appends grammars to r.
+func (r *Registry) Register(grammars ...Grammar) {
+	r.grammars = append(r.grammars, grammars...)
+}
+
+// Grammars returns the registered grammars in order.
+func (r *Registry) Grammars() []Grammar {
+	return append([]Grammar(nil), r.grammars...)
+}
+
+// Parse parses s as a client-side statement. s must not contain the terminator.
+// Comments in s are ignored if s can be lexed.
+func (r *Registry) Parse(s string) (Statement, bool) {
+	if stripped, err := gsqlutils.StripComments("", s); err == nil {
+		s = stripped
+	}
+
+	for _, g := range r.grammars {
+		matches := g.Pattern.FindStringSubmatchIndex(s)
+		if matches == nil {
+			continue
+		}
+
+		args := make(map[string]string)
+		for i, name := range g.Pattern.SubexpNames() {
+			if name == "" || matches[2*i] < 0 {
+				continue
+			}
+			args[name] = strings.TrimSpace(s[matches[2*i]:matches[2*i+1]])
+		}
+		return Statement{Name: g.Name, Args: args}, true
+	}
+	return Statement{}, false
+}
+
+var defaultGrammars = []Grammar{
+	MustGrammar("SHOW VARIABLE", `SHOW\s+VARIABLE\s+(?P<name>[^\s=]+)`),
+	MustGrammar("SET", `SET\s+(?:(?P<local>LOCAL)\s+)?(?P<name>[^\s=]+)\s*=\s*(?P<value>.*\S)`),
+	MustGrammar("BEGIN", `(?:BEGIN(?:\s+TRANSACTION)?|START\s+TRANSACTION)(?:\s+(?P<mode>READ\s+ONLY|READ\s+WRITE))?`),
+	MustGrammar("COMMIT", `COMMIT(?:\s+TRANSACTION)?`),
+	MustGrammar("ROLLBACK", `ROLLBACK(?:\s+TRANSACTION)?`),
+	MustGrammar("START BATCH", `START\s+BATCH\s+(?P<type>DDL|DML)`),
+	MustGrammar("RUN BATCH", `RUN\s+BATCH`),
+	MustGrammar("ABORT BATCH", `ABORT\s+BATCH`),
+	MustGrammar("USE", `USE\s+(?P<database>[^\s]+)(?:\s+ROLE\s+(?P<role>[^\s]+))?`),
+	MustGrammar("SHOW DATABASES", `SHOW\s+DATABASES`),
+	MustGrammar("SHOW TABLES", `SHOW\s+TABLES(?:\s+(?P<schema>[^\s]+))?`),
+	MustGrammar("SHOW CREATE", `SHOW\s+CREATE\s+(?P<type>TABLE|INDEX)\s+(?P<name>[^\s]+)`),
+	MustGrammar("SHOW COLUMNS", `SHOW\s+COLUMNS\s+FROM\s+(?P<table>[^\s]+)`),
+	MustGrammar("SHOW INDEX", `SHOW\s+(?:INDEX|INDEXES|KEYS)\s+FROM\s+(?P<table>[^\s]+)`),
+	MustGrammar("DESCRIBE", `DESCRIBE\s+(?P<statement>.+)`),
+	MustGrammar("EXPLAIN", `EXPLAIN\s+(?:(?P<analyze>ANALYZE)\s+)?(?P<statement>.+)`),
+}
@@ -0,0 +1,104 @@
+package clientstmt_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils/clientstmt"
+)
+
+func TestRegistry_Parse(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  clientstmt.Statement
+		ok    bool
+	}{
+		{
+			desc:  "SHOW VARIABLE",
+			input: "show variable AUTOCOMMIT",
+			want:  clientstmt.Statement{Name: "SHOW VARIABLE", Args: map[string]string{"name": "AUTOCOMMIT"}},
+			ok:    true,
+		},
+		{
+			desc:  "SET",
+			input: "SET AUTOCOMMIT = false",
+			want:  clientstmt.Statement{Name: "SET", Args: map[string]string{"name": "AUTOCOMMIT", "value": "false"}},
+			ok:    true,
+		},
+		{
+			desc:  "SET LOCAL with comments",
+			input: "-- comment\nSET LOCAL READ_ONLY_STALENESS='MAX_STALENESS 10s' /* comment */",
+			want:  clientstmt.Statement{Name: "SET", Args: map[string]string{"local": "LOCAL", "name": "READ_ONLY_STALENESS", "value": "'MAX_STALENESS 10s'"}},
+			ok:    true,
+		},
+		{
+			desc:  "BEGIN",
+			input: "BEGIN TRANSACTION READ ONLY",
+			want:  clientstmt.Statement{Name: "BEGIN", Args: map[string]string{"mode": "READ ONLY"}},
+			ok:    true,
+		},
+		{
+			desc:  "START BATCH",
+			input: "START BATCH DDL",
+			want:  clientstmt.Statement{Name: "START BATCH", Args: map[string]string{"type": "DDL"}},
+			ok:    true,
+		},
+		{
+			desc:  "USE",
+			input: "USE my-db ROLE reader",
+			want:  clientstmt.Statement{Name: "USE", Args: map[string]string{"database": "my-db", "role": "reader"}},
+			ok:    true,
+		},
+		{
+			desc:  "SHOW TABLES",
+			input: "SHOW TABLES",
+			want:  clientstmt.Statement{Name: "SHOW TABLES", Args: map[string]string{}},
+			ok:    true,
+		},
+		{
+			desc:  "EXPLAIN ANALYZE",
+			input: "EXPLAIN ANALYZE SELECT 1",
+			want:  clientstmt.Statement{Name: "EXPLAIN", Args: map[string]string{"analyze": "ANALYZE", "statement": "SELECT 1"}},
+			ok:    true,
+		},
+		{
+			desc:  "GoogleSQL statement",
+			input: "SELECT 1",
+		},
+		{
+			desc:  "partial match",
+			input: "COMMIT NOW",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, ok := clientstmt.DefaultRegistry().Parse(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in statement: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := clientstmt.DefaultRegistry()
+	registry.Register(clientstmt.MustGrammar("SHOW OPERATION", `SHOW\s+OPERATION\s+(?P<id>\S+)`))
+
+	got, ok := registry.Parse("SHOW OPERATION op1")
+	if !ok {
+		t.Fatal("should match, but not matched")
+	}
+
+	want := clientstmt.Statement{Name: "SHOW OPERATION", Args: map[string]string{"id": "op1"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in statement: (-want +got):\n%s", diff)
+	}
+
+	if _, err := clientstmt.NewGrammar("INVALID", `(`); err == nil {
+		t.Error("NewGrammar should fail with an invalid pattern, but success")
+	}
+}
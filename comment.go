@@ -0,0 +1,225 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+	"spheric.cloud/xiter"
+)
+
+// CommentedStatement is a view of RawStatement which classifies comments of the statement.
+// All positions are offsets in the whole input.
+type CommentedStatement struct {
+	RawStatement
+
+	// Leading are comments before the first token, they are considered as documentation of the statement.
+	// Comments in the same line as the previous terminator are not included.
+	Leading []token.TokenComment
+
+	// Inline are comments after the first token and before the terminator.
+	Inline []token.TokenComment
+
+	// Trailing are comments after the terminator in the same line, e.g. `CREATE TABLE ...; -- v2 table`.
+	Trailing []token.TokenComment
+}
+
+// SeparateInputWithComments separates an input string to statements like SeparateInput, and classifies comments.
+// Unlike SeparateInput, Pos and Statement of each statement don't contain trailing comments of the previous statement.
+// filepath can be empty, it is only used in error message.
+func SeparateInputWithComments(filepath, s string, opts ...SeparateOption) ([]CommentedStatement, error) {
+	stmts, err := SeparateInput(filepath, s, opts...)
+
+	var results []CommentedStatement
+	for _, stmt := range stmts {
+		cstmt, firstPos, hasToken := classifyComments(filepath, s, stmt)
+
+		if prev := len(results) - 1; prev >= 0 && results[prev].Terminator != "" {
+			prevEnd := results[prev].End
+			for len(cstmt.Leading) > 0 && !strings.Contains(s[prevEnd:cstmt.Leading[0].Pos], "\n") {
+				results[prev].Trailing = append(results[prev].Trailing, cstmt.Leading[0])
+				cstmt.Leading = cstmt.Leading[1:]
+			}
+			if len(cstmt.Leading) == 0 {
+				cstmt.Leading = nil
+			}
+		}
+
+		// Statement of only trailing comments of the previous statement is not a statement.
+		if !hasToken && len(cstmt.Leading) == 0 && cstmt.Terminator == "" {
+			continue
+		}
+
+		// The statement starts at the first remaining leading comment, or the first token if all of them are trailing comments.
+		start := firstPos
+		if len(cstmt.Leading) > 0 {
+			start = cstmt.Leading[0].Pos
+		}
+		if start > cstmt.Pos {
+			stmtEnd := cstmt.Pos + token.Pos(len(cstmt.Statement))
+			cstmt.Pos = start
+			cstmt.Statement = s[cstmt.Pos:stmtEnd]
+		}
+		results = append(results, cstmt)
+	}
+	return results, err
+}
+
+// classifyComments classifies comments in stmt to leading and inline comments.
+// It also returns the position of the first token, which is the end of stmt if stmt has no token, and whether stmt has any token.
+func classifyComments(filepath, s string, stmt RawStatement) (CommentedStatement, token.Pos, bool) {
+	cstmt := CommentedStatement{RawStatement: stmt}
+
+	// Lex from preceding whitespaces to preserve token.TokenComment.Space of the first comment.
+	start := token.Pos(len(strings.TrimRightFunc(s[:stmt.Pos], unicode.IsSpace)))
+	end := stmt.Pos + token.Pos(len(stmt.Statement))
+
+	firstPos := end
+	var hasToken bool
+	seq := xiter.MapKeys(NewLexerSeq(filepath, s[start:end]), func(tok token.Token) token.Token {
+		return shiftToken(tok, start)
+	})
+	for tok, err := range seq {
+		// Comments in an erroneous statement are ignored.
+		if err != nil {
+			break
+		}
+
+		if hasToken {
+			cstmt.Inline = append(cstmt.Inline, tok.Comments...)
+		} else {
+			cstmt.Leading = append(cstmt.Leading, tok.Comments...)
+			firstPos = tok.Pos
+		}
+
+		if tok.Kind == token.TokenEOF {
+			break
+		}
+		hasToken = true
+	}
+	return cstmt, firstPos, hasToken
+}
+
+// CommentStyle is a style of a comment.
+type CommentStyle int
+
+const (
+	CommentStyleInvalid CommentStyle = iota
+
+	// CommentStyleDash is a line comment starting with `--`.
+	CommentStyleDash
+
+	// CommentStyleHash is a line comment starting with `#`.
+	CommentStyleHash
+
+	// CommentStyleBlock is a block comment `/* ... */`.
+	CommentStyleBlock
+)
+
+func (s CommentStyle) String() string {
+	switch s {
+	case CommentStyleDash:
+		return "Dash"
+	case CommentStyleHash:
+		return "Hash"
+	case CommentStyleBlock:
+		return "Block"
+	case CommentStyleInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(s))
+	}
+}
+
+// CommentPlacement is a placement of a comment in a statement, see CommentedStatement.
+type CommentPlacement int
+
+const (
+	CommentPlacementInvalid CommentPlacement = iota
+	CommentPlacementLeading
+	CommentPlacementInline
+	CommentPlacementTrailing
+)
+
+func (p CommentPlacement) String() string {
+	switch p {
+	case CommentPlacementLeading:
+		return "Leading"
+	case CommentPlacementInline:
+		return "Inline"
+	case CommentPlacementTrailing:
+		return "Trailing"
+	case CommentPlacementInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(p))
+	}
+}
+
+// Comment is a comment in an input with the statement it belongs to.
+type Comment struct {
+	token.TokenComment
+
+	Style CommentStyle
+
+	// Text is the content without comment markers and surrounding whitespaces, e.g. `name: GetUser :one`.
+	Text string
+
+	// Statement is the index of the statement in the result of SeparateInputWithComments.
+	Statement int
+
+	Placement CommentPlacement
+
+	Range Range
+}
+
+// ExtractComments returns all comments in s in order of positions.
+// Statements and placements are decided like SeparateInputWithComments, and opts are passed to it.
+// filepath can be empty, it is only used in error message.
+func ExtractComments(filepath, s string, opts ...SeparateOption) ([]Comment, error) {
+	stmts, err := SeparateInputWithComments(filepath, s, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error on ExtractComments, err: %w", err)
+	}
+
+	idx := NewPositionIndex(s)
+	var result []Comment
+	for i, stmt := range stmts {
+		for _, group := range []struct {
+			placement CommentPlacement
+			comments  []token.TokenComment
+		}{
+			{CommentPlacementLeading, stmt.Leading},
+			{CommentPlacementInline, stmt.Inline},
+			{CommentPlacementTrailing, stmt.Trailing},
+		} {
+			for _, c := range group.comments {
+				style, text := parseComment(c.Raw)
+				result = append(result, Comment{
+					TokenComment: c,
+					Style:        style,
+					Text:         text,
+					Statement:    i,
+					Placement:    group.placement,
+					Range:        idx.Range(c.Pos, c.End),
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// parseComment returns the style and the content of raw.
+func parseComment(raw string) (CommentStyle, string) {
+	switch {
+	case strings.HasPrefix(raw, "--"):
+		return CommentStyleDash, strings.TrimSpace(raw[len("--"):])
+	case strings.HasPrefix(raw, "#"):
+		return CommentStyleHash, strings.TrimSpace(raw[len("#"):])
+	case strings.HasPrefix(raw, "/*"):
+		return CommentStyleBlock, strings.TrimSpace(strings.TrimSuffix(raw[len("/*"):], "*/"))
+	default:
+		return CommentStyleInvalid, raw
+	}
+}
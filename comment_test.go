@@ -0,0 +1,141 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSeparateInputWithComments(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []gsqlutils.CommentedStatement
+	}{
+		{
+			desc:  "no comment",
+			input: "SELECT 1",
+			want: []gsqlutils.CommentedStatement{
+				{RawStatement: gsqlutils.RawStatement{Statement: "SELECT 1", Pos: 0, End: 8}},
+			},
+		},
+		{
+			desc: "leading, inline and trailing comments",
+			input: "-- Singers table\n" +
+				"CREATE TABLE Singers (SingerId INT64 /* key */) PRIMARY KEY (SingerId); -- v2 table\n" +
+				"/* Albums table */ CREATE TABLE Albums (AlbumId INT64) PRIMARY KEY (AlbumId) -- interleave later\n" +
+				"; # after terminator\n",
+			want: []gsqlutils.CommentedStatement{
+				{
+					RawStatement: gsqlutils.RawStatement{
+						Statement:  "-- Singers table\nCREATE TABLE Singers (SingerId INT64 /* key */) PRIMARY KEY (SingerId)",
+						Pos:        0,
+						End:        88,
+						Terminator: ";",
+					},
+					Leading:  []token.TokenComment{{Raw: "-- Singers table\n", Pos: 0, End: 17}},
+					Inline:   []token.TokenComment{{Space: " ", Raw: "/* key */", Pos: 54, End: 63}},
+					Trailing: []token.TokenComment{{Space: " ", Raw: "-- v2 table\n", Pos: 89, End: 101}},
+				},
+				{
+					RawStatement: gsqlutils.RawStatement{
+						Statement:  "/* Albums table */ CREATE TABLE Albums (AlbumId INT64) PRIMARY KEY (AlbumId) -- interleave later\n",
+						Pos:        101,
+						End:        199,
+						Terminator: ";",
+					},
+					Leading:  []token.TokenComment{{Raw: "/* Albums table */", Pos: 101, End: 119}},
+					Inline:   []token.TokenComment{{Space: " ", Raw: "-- interleave later\n", Pos: 178, End: 198}},
+					Trailing: []token.TokenComment{{Space: " ", Raw: "# after terminator\n", Pos: 200, End: 219}},
+				},
+			},
+		},
+		{
+			desc:  "comment in the next line is not trailing",
+			input: "SELECT 1; /* a */ /* b */\n/* c */\nSELECT 2",
+			want: []gsqlutils.CommentedStatement{
+				{
+					RawStatement: gsqlutils.RawStatement{Statement: "SELECT 1", Pos: 0, End: 9, Terminator: ";"},
+					Trailing: []token.TokenComment{
+						{Space: " ", Raw: "/* a */", Pos: 10, End: 17},
+						{Space: " ", Raw: "/* b */", Pos: 18, End: 25},
+					},
+				},
+				{
+					RawStatement: gsqlutils.RawStatement{Statement: "/* c */\nSELECT 2", Pos: 26, End: 42},
+					Leading:      []token.TokenComment{{Space: "\n", Raw: "/* c */", Pos: 26, End: 33}},
+				},
+			},
+		},
+		{
+			desc:  "all leading comments are trailing comments of the previous statement",
+			input: "SELECT 1; -- c\nSELECT 2",
+			want: []gsqlutils.CommentedStatement{
+				{
+					RawStatement: gsqlutils.RawStatement{Statement: "SELECT 1", Pos: 0, End: 9, Terminator: ";"},
+					Trailing:     []token.TokenComment{{Space: " ", Raw: "-- c\n", Pos: 10, End: 15}},
+				},
+				{
+					RawStatement: gsqlutils.RawStatement{Statement: "SELECT 2", Pos: 15, End: 23},
+				},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.SeparateInputWithComments("", tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExtractComments(t *testing.T) {
+	type comment struct {
+		Style     string
+		Text      string
+		Statement int
+		Placement string
+		Range     string
+	}
+
+	input := "-- +migrate Up\n" +
+		"-- name: GetUser :one\n" +
+		"SELECT * FROM Users WHERE id = @id; -- gsqlutils:ignore lint-rule\n" +
+		"/* block */ SELECT 1 # hash\n" +
+		";\n" +
+		"-- +migrate Down\n"
+
+	comments, err := gsqlutils.ExtractComments("", input)
+	if err != nil {
+		t.Fatalf("should success, but failed: %v", err)
+	}
+
+	var got []comment
+	for _, c := range comments {
+		got = append(got, comment{
+			Style:     c.Style.String(),
+			Text:      c.Text,
+			Statement: c.Statement,
+			Placement: c.Placement.String(),
+			Range:     c.Range.Start.String() + "-" + c.Range.End.String(),
+		})
+	}
+
+	want := []comment{
+		{Style: "Dash", Text: "+migrate Up", Statement: 0, Placement: "Leading", Range: "1:1-2:1"},
+		{Style: "Dash", Text: "name: GetUser :one", Statement: 0, Placement: "Leading", Range: "2:1-3:1"},
+		{Style: "Dash", Text: "gsqlutils:ignore lint-rule", Statement: 0, Placement: "Trailing", Range: "3:37-4:1"},
+		{Style: "Block", Text: "block", Statement: 1, Placement: "Leading", Range: "4:1-4:12"},
+		{Style: "Hash", Text: "hash", Statement: 1, Placement: "Inline", Range: "4:22-5:1"},
+		{Style: "Dash", Text: "+migrate Down", Statement: 2, Placement: "Leading", Range: "6:1-7:1"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in result: (-want +got):\n%s", diff)
+	}
+}
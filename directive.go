@@ -0,0 +1,103 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DirectiveKind is a kind of directive comments.
+type DirectiveKind int
+
+const (
+	DirectiveInvalid DirectiveKind = iota
+
+	// DirectiveGsqlutils is a directive for this package and tools built on it, e.g. `-- gsqlutils:ignore lint-rule`.
+	DirectiveGsqlutils
+
+	// DirectiveMigrate is a directive of sql-migrate, e.g. `-- +migrate Up`, `-- +migrate StatementBegin`.
+	DirectiveMigrate
+
+	// DirectiveQueryName is a query name annotation of sqlc, e.g. `-- name: GetUser :one`.
+	DirectiveQueryName
+)
+
+func (k DirectiveKind) String() string {
+	switch k {
+	case DirectiveGsqlutils:
+		return "Gsqlutils"
+	case DirectiveMigrate:
+		return "Migrate"
+	case DirectiveQueryName:
+		return "QueryName"
+	case DirectiveInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(k))
+	}
+}
+
+// Directive is a structured directive comment.
+type Directive struct {
+	Kind DirectiveKind
+
+	// Name is the command of DirectiveGsqlutils, e.g. `ignore`,
+	// the command of DirectiveMigrate, e.g. `Up`, `Down`, `StatementBegin`, `StatementEnd`,
+	// or the query name of DirectiveQueryName, e.g. `GetUser`.
+	Name string
+
+	// Args are the following words, e.g. `lint-rule` of `gsqlutils:ignore`, `notransaction` of `+migrate Up`, `:one` of `name: GetUser`.
+	Args []string
+
+	Comment Comment
+}
+
+const (
+	gsqlutilsDirectivePrefix = "gsqlutils:"
+	migrateDirectivePrefix   = "+migrate"
+	queryNameDirectivePrefix = "name:"
+)
+
+// ParseDirective parses c as a directive comment. It returns false if c is not a directive.
+// Directives can be written in any comment style, and their prefixes are case-sensitive.
+func ParseDirective(c Comment) (Directive, bool) {
+	kind, rest := DirectiveInvalid, ""
+	switch {
+	case strings.HasPrefix(c.Text, gsqlutilsDirectivePrefix):
+		kind, rest = DirectiveGsqlutils, c.Text[len(gsqlutilsDirectivePrefix):]
+
+		// The command must follow the prefix without spaces.
+		if rest == "" || strings.TrimLeft(rest, " \t") != rest {
+			return Directive{}, false
+		}
+	case strings.HasPrefix(c.Text, migrateDirectivePrefix+" "), strings.HasPrefix(c.Text, migrateDirectivePrefix+"\t"):
+		kind, rest = DirectiveMigrate, c.Text[len(migrateDirectivePrefix):]
+	case strings.HasPrefix(c.Text, queryNameDirectivePrefix):
+		kind, rest = DirectiveQueryName, c.Text[len(queryNameDirectivePrefix):]
+	default:
+		return Directive{}, false
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return Directive{}, false
+	}
+	return Directive{Kind: kind, Name: fields[0], Args: fields[1:], Comment: c}, true
+}
+
+// ExtractDirectives returns all directive comments in s in order of positions.
+// Comments which are not directives are ignored. opts are passed to SeparateInputWithComments.
+// filepath can be empty, it is only used in error message.
+func ExtractDirectives(filepath, s string, opts ...SeparateOption) ([]Directive, error) {
+	comments, err := ExtractComments(filepath, s, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error on ExtractDirectives, err: %w", err)
+	}
+
+	var result []Directive
+	for _, c := range comments {
+		if d, ok := ParseDirective(c); ok {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestExtractDirectives(t *testing.T) {
+	type directive struct {
+		Kind      string
+		Name      string
+		Args      []string
+		Statement int
+	}
+
+	input := "-- +migrate Up notransaction\n" +
+		"-- name: GetUser :one\n" +
+		"SELECT * FROM Users WHERE id = @id; -- gsqlutils:ignore lint-rule other-rule\n" +
+		"/* name: ListUsers :many */ SELECT * FROM Users;\n" +
+		"-- gsqlutils: not a directive\n" +
+		"-- +migrateUp is not a directive\n" +
+		"-- just a comment\n" +
+		"SELECT 1;\n" +
+		"-- +migrate Down\n"
+
+	directives, err := gsqlutils.ExtractDirectives("", input)
+	if err != nil {
+		t.Fatalf("should success, but failed: %v", err)
+	}
+
+	var got []directive
+	for _, d := range directives {
+		got = append(got, directive{Kind: d.Kind.String(), Name: d.Name, Args: d.Args, Statement: d.Comment.Statement})
+	}
+
+	want := []directive{
+		{Kind: "Migrate", Name: "Up", Args: []string{"notransaction"}, Statement: 0},
+		{Kind: "QueryName", Name: "GetUser", Args: []string{":one"}, Statement: 0},
+		{Kind: "Gsqlutils", Name: "ignore", Args: []string{"lint-rule", "other-rule"}, Statement: 0},
+		{Kind: "QueryName", Name: "ListUsers", Args: []string{":many"}, Statement: 1},
+		{Kind: "Migrate", Name: "Down", Args: []string{}, Statement: 3},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in result: (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,151 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/samber/lo"
+
+	"github.com/apstndb/gsqlutils/internal"
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+// QueryFingerprint is a normalized shape of a statement.
+type QueryFingerprint struct {
+	// Normalized is the normalized text of the statement.
+	Normalized string
+
+	// Hash is the 64-bit FNV-1a hash of Normalized.
+	Hash uint64
+}
+
+// String returns Hash as a 16 digits hexadecimal string.
+func (f QueryFingerprint) String() string {
+	return fmt.Sprintf("%016x", f.Hash)
+}
+
+// placeholderRaw is a placeholder of literals in fingerprints.
+const placeholderRaw = "?"
+
+// fingerprintKeywordLikes are non-reserved keywords which are normalized in fingerprints in addition to reserved keywords.
+var fingerprintKeywordLikes = []string{
+	"INSERT", "UPDATE", "DELETE", "VALUES", "REPLACE", "RETURN", "OFFSET",
+	"CALL", "GRAPH", "MATCH", "OPTIONAL", "LET", "FILTER", "NEXT",
+}
+
+// Fingerprint lexes a statement and returns its normalized shape, like pg_stat_statements.
+// Comments and hints are removed, keywords are uppercased, and numeric, string and bytes literals become `?` with their unary minus.
+// A list of literals in `IN (...)` or an array literal `[...]` collapses into a single `?`.
+// NULL, TRUE and FALSE are not replaced, because they are keywords which can't be query parameters in `IS NULL` or `IS TRUE`,
+// and they often change the shape of the query plan.
+// Tokens are separated like SimpleStripComments, so statements which differ only in whitespaces have the same fingerprint.
+// Trailing semicolons are ignored.
+// filepath can be empty, it is only used in error message.
+func Fingerprint(filepath, s string) (QueryFingerprint, error) {
+	seq := tokenfilter.NormalizeKeywordCase(
+		tokenfilter.StripComments(newStripHintsSeq(filepath, s)),
+		tokenfilter.KeywordCaseUpper, fingerprintKeywordLikes...)
+
+	var tokens []token.Token
+	for tok, err := range seq {
+		if err != nil {
+			return QueryFingerprint{}, fmt.Errorf("error on Fingerprint, err: %w", err)
+		}
+		if tok.Kind == token.TokenEOF {
+			break
+		}
+		tokens = append(tokens, tok)
+	}
+
+	tokens = collapseLiteralLists(replaceLiterals(tokens))
+	for len(tokens) > 0 && tokens[len(tokens)-1].Kind == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	normalized, err := tryUnlexTokenSeq(false, tokenSliceSeq(tokens))
+	if err != nil {
+		return QueryFingerprint{}, fmt.Errorf("error on Fingerprint, err: %w", err)
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(normalized))
+	return QueryFingerprint{Normalized: normalized, Hash: h.Sum64()}, nil
+}
+
+// isLiteralToken is true if tok is a numeric, string or bytes literal.
+func isLiteralToken(tok token.Token) bool {
+	return internal.OneOf(tok.Kind, token.TokenInt, token.TokenFloat, token.TokenString, token.TokenBytes)
+}
+
+// isOperandEnd is true if an expression can end with kind, so the following "-" is a binary operator.
+func isOperandEnd(kind token.TokenKind) bool {
+	return internal.OneOf(kind,
+		token.TokenIdent, token.TokenParam, token.TokenInt, token.TokenFloat, token.TokenString, token.TokenBytes,
+		placeholderRaw, ")", "]", "NULL", "TRUE", "FALSE", "END")
+}
+
+// replaceLiterals replaces literals and their unary minus with placeholders.
+func replaceLiterals(tokens []token.Token) []token.Token {
+	var result []token.Token
+	for _, tok := range tokens {
+		if !isLiteralToken(tok) {
+			result = append(result, tok)
+			continue
+		}
+
+		placeholder := token.Token{Kind: placeholderRaw, Raw: placeholderRaw, Pos: tok.Pos, End: tok.End}
+		if last, ok := lo.Last(result); ok && last.Kind == "-" && !isOperandEnd(prevKind(result, 2)) {
+			placeholder.Pos = last.Pos
+			result = result[:len(result)-1]
+		}
+		result = append(result, placeholder)
+	}
+	return result
+}
+
+// collapseLiteralLists collapses `IN (?, ?)` and `[?, ?]` into `IN (?)` and `[?]`.
+// Lists which contain anything other than placeholders are not changed.
+func collapseLiteralLists(tokens []token.Token) []token.Token {
+	var result []token.Token
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		result = append(result, tok)
+
+		var closing token.TokenKind
+		switch {
+		case tok.Kind == "(" && prevKind(tokens[:i], 1) == "IN":
+			closing = ")"
+		case tok.Kind == "[" && !isOperandEnd(prevKind(tokens[:i], 1)):
+			closing = "]"
+		default:
+			continue
+		}
+
+		end, ok := placeholderListEnd(tokens, i+1, closing)
+		if !ok {
+			continue
+		}
+		result = append(result, tokens[i+1], tokens[end])
+		i = end
+	}
+	return result
+}
+
+// placeholderListEnd returns the index of closing if tokens[start:] is a non-empty comma separated list of placeholders followed by closing.
+func placeholderListEnd(tokens []token.Token, start int, closing token.TokenKind) (int, bool) {
+	for i := start; i < len(tokens); i += 2 {
+		if tokens[i].Kind != placeholderRaw || i+1 >= len(tokens) {
+			return 0, false
+		}
+		switch tokens[i+1].Kind {
+		case closing:
+			return i + 1, true
+		case ",":
+			continue
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,91 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestFingerprint(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  string
+	}{
+		{
+			desc:  "literals, comments and keyword case",
+			input: "select * from t where a = 1 and b = 'x' -- comment\n;",
+			want:  "SELECT * FROM t WHERE a = ? AND b = ?",
+		},
+		{
+			desc:  "unary minus",
+			input: "SELECT a - -1, -1.5, a-1 FROM t",
+			want:  "SELECT a - ?, ?, a - ? FROM t",
+		},
+		{
+			desc:  "hints and lists",
+			input: "@{OPTIMIZER_VERSION=7} SELECT * FROM t @{FORCE_INDEX=idx} WHERE a IN (1, 2, -3) AND b IN UNNEST([b'x', b'y']) AND c IN (@p, 1)",
+			want:  "SELECT * FROM t WHERE a IN (?) AND b IN UNNEST([?]) AND c IN (@p, ?)",
+		},
+		{
+			desc:  "DML",
+			input: "insert into t (a, b) values (1, 'a'), (2, 'b') then return a",
+			want:  "INSERT INTO t (a, b) VALUES (?, ?), (?, ?) THEN RETURN a",
+		},
+		{
+			desc:  "typed literals and subscripts",
+			input: "SELECT DATE '2020-01-01', arr[OFFSET(0)] FROM t LIMIT 10",
+			want:  "SELECT DATE ?, arr[OFFSET(?)] FROM t LIMIT ?",
+		},
+		{
+			desc:  "array literals",
+			input: "SELECT [1,2], ARRAY[3, 4], arr[0] FROM t",
+			want:  "SELECT [?], ARRAY[?], arr[?] FROM t",
+		},
+		{
+			desc:  "NULL, TRUE and FALSE are not replaced",
+			input: "SELECT * FROM t WHERE a IS NULL AND b = TRUE AND c IS NOT FALSE",
+			want:  "SELECT * FROM t WHERE a IS NULL AND b = TRUE AND c IS NOT FALSE",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.Fingerprint("", tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.Normalized); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("same shape", func(t *testing.T) {
+		a, err := gsqlutils.Fingerprint("", "SELECT * FROM t WHERE id IN (1, 2, 3) AND name = 'foo'")
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+		b, err := gsqlutils.Fingerprint("", "/* app */ select *\nfrom t where id in (4) and name = \"bar\";")
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+		if a.Hash != b.Hash || a.String() != b.String() {
+			t.Errorf("fingerprints should be same, but differ: %q, %q", a.Normalized, b.Normalized)
+		}
+
+		c, err := gsqlutils.Fingerprint("", "SELECT * FROM t WHERE id IN (1, 2, 3) AND name = @name")
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+		if a.Hash == c.Hash {
+			t.Errorf("fingerprints should differ, but same: %q, %q", a.Normalized, c.Normalized)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := gsqlutils.Fingerprint("", "SELECT 'unclosed"); err == nil {
+			t.Error("should fail, but success")
+		}
+	})
+}
@@ -0,0 +1,667 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/samber/lo"
+
+	"github.com/apstndb/gsqlutils/keywords"
+)
+
+// FormatOption is an option of Format.
+type FormatOption func(*formatter)
+
+// WithWidth sets the preferred maximum width of lines in runes. The default is 80.
+// Lines can exceed it if there is no place to wrap.
+func WithWidth(width int) FormatOption {
+	return func(f *formatter) {
+		f.width = width
+	}
+}
+
+// WithIndent sets the string of one indentation level. The default is two spaces.
+func WithIndent(indent string) FormatOption {
+	return func(f *formatter) {
+		f.indent = indent
+	}
+}
+
+type formatter struct {
+	width  int
+	indent string
+}
+
+// Format formats SQL statements in s without parsing.
+// Clauses of queries and DML statements start at new lines, subqueries are indented,
+// and lists in parentheses and clauses are wrapped if they don't fit in the width.
+// Comments and hints are preserved. A comment stays on its own line if it was on its own line,
+// otherwise it follows the previous token.
+// It is idempotent, and it returns an error instead of changing the token sequence.
+// filepath can be empty, it is only used in error message.
+func Format(filepath, s string, opts ...FormatOption) (string, error) {
+	f := &formatter{width: 80, indent: "  "}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	var tokens []token.Token
+	for tok, err := range NewLexerSeq(filepath, s) {
+		if err != nil {
+			return "", fmt.Errorf("can't format, err: %w", err)
+		}
+		tokens = append(tokens, tok)
+	}
+
+	p := &printer{formatter: f, sp: newSpacer(), brk: -1}
+	eof := tokens[len(tokens)-1]
+	for stmt := range splitAfterSemicolons(tokens[:len(tokens)-1]) {
+		p.statement(stmt)
+	}
+	p.comments(eof)
+
+	result := p.b.String()
+	if result != "" && !strings.HasSuffix(result, "\n") {
+		result += "\n"
+	}
+
+	if err := verifyFormat(filepath, s, result); err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// splitAfterSemicolons splits tokens after each `;`.
+func splitAfterSemicolons(tokens []token.Token) func(yield func([]token.Token) bool) {
+	return func(yield func([]token.Token) bool) {
+		for len(tokens) > 0 {
+			i := slices.IndexFunc(tokens, func(tok token.Token) bool { return tok.Kind == ";" })
+			if i < 0 {
+				i = len(tokens) - 1
+			}
+			if !yield(tokens[:i+1]) {
+				return
+			}
+			tokens = tokens[i+1:]
+		}
+	}
+}
+
+// verifyFormat verifies formatted has the same tokens and comments as s.
+func verifyFormat(filepath, s, formatted string) error {
+	original, err := formatTokenSeq(filepath, s)
+	if err != nil {
+		return err
+	}
+
+	result, err := formatTokenSeq(filepath, formatted)
+	if err != nil {
+		return fmt.Errorf("BUG: formatted output can't be lexed, err: %w", err)
+	}
+
+	if !slices.Equal(original, result) {
+		return fmt.Errorf("BUG: formatted output changes tokens")
+	}
+	return nil
+}
+
+// formatTokenSeq returns raw strings of tokens and comments in s, trailing newlines of comments are trimmed.
+func formatTokenSeq(filepath, s string) ([]string, error) {
+	var result []string
+	for tok, err := range NewLexerSeq(filepath, s) {
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range tok.Comments {
+			result = append(result, strings.TrimRight(c.Raw, "\r\n"))
+		}
+		result = append(result, tok.Raw)
+	}
+	return result, nil
+}
+
+type fmtGroupKind int
+
+const (
+	// fmtGroupList is a parenthesized list, it is wrapped at commas if it doesn't fit in a line.
+	fmtGroupList fmtGroupKind = iota
+
+	// fmtGroupQuery is a parenthesized query, it is always indented.
+	fmtGroupQuery
+
+	// fmtGroupAtom is brackets, braces or a hint, it is never wrapped.
+	fmtGroupAtom
+)
+
+// fmtNode is a token or a bracketed group.
+type fmtNode struct {
+	tok   token.Token
+	group *fmtGroup
+}
+
+type fmtGroup struct {
+	kind  fmtGroupKind
+	open  token.Token
+	nodes []fmtNode
+
+	// close is the closing token, it is invalid if the group is not closed.
+	close token.Token
+}
+
+var fmtClosingKinds = map[token.TokenKind]token.TokenKind{
+	"(": ")",
+	"[": "]",
+	"{": "}",
+}
+
+// buildNodes builds trees of groups. Unmatched closing tokens are treated as normal tokens.
+func buildNodes(tokens []token.Token) []fmtNode {
+	root := &fmtGroup{}
+	stack := []*fmtGroup{root}
+	for i, tok := range tokens {
+		top := stack[len(stack)-1]
+		switch tok.Kind {
+		case "(", "[", "{":
+			kind := fmtGroupAtom
+			if tok.Kind == "(" {
+				next := nthToken(tokens, i+1)
+				kind = fmtGroupList
+				if next.Kind == "SELECT" || next.Kind == "WITH" {
+					kind = fmtGroupQuery
+				}
+			}
+			g := &fmtGroup{kind: kind, open: tok, close: invalidToken}
+			top.nodes = append(top.nodes, fmtNode{group: g})
+			stack = append(stack, g)
+		case ")", "]", "}":
+			if len(stack) > 1 && fmtClosingKinds[top.open.Kind] == tok.Kind {
+				top.close = tok
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			top.nodes = append(top.nodes, fmtNode{tok: tok})
+		default:
+			top.nodes = append(top.nodes, fmtNode{tok: tok})
+		}
+	}
+	return root.nodes
+}
+
+// flattenNodes returns tokens of nodes in order.
+func flattenNodes(nodes []fmtNode) []token.Token {
+	var tokens []token.Token
+	for _, n := range nodes {
+		if n.group == nil {
+			tokens = append(tokens, n.tok)
+			continue
+		}
+		tokens = append(tokens, n.group.open)
+		tokens = append(tokens, flattenNodes(n.group.nodes)...)
+		if !n.group.close.Pos.Invalid() {
+			tokens = append(tokens, n.group.close)
+		}
+	}
+	return tokens
+}
+
+// isKeywordNode is true if n is a keyword or a keyword-like identifier in candidates.
+func isKeywordNode(n fmtNode, candidates ...string) bool {
+	return n.group == nil && keywords.MatchAny(n.tok, candidates...)
+}
+
+// isHintNodes is true if nodes are only hints.
+func isHintNodes(nodes []fmtNode) bool {
+	for i := 0; i < len(nodes); i += 2 {
+		if i+1 >= len(nodes) || nodes[i].group != nil || nodes[i].tok.Kind != "@" || nodes[i+1].group == nil {
+			return false
+		}
+	}
+	return true
+}
+
+type printer struct {
+	*formatter
+	b  strings.Builder
+	sp *spacer
+
+	// col is the width of the current line in runes.
+	col int
+
+	// level is the indentation level of the current line.
+	level int
+
+	// brk is the indentation level of the line break requested before the next token, it is -1 if no break is requested.
+	// It is lazy to put comments following the previous token before the line break.
+	brk int
+
+	// afterLineComment is true if the last written one is a line comment.
+	afterLineComment bool
+
+	// afterOwnLineComment is true if the last written one is a block comment on its own line.
+	afterOwnLineComment bool
+
+	// afterComment is true if the last written one is a comment.
+	afterComment bool
+
+	prevRaw string
+}
+
+func (p *printer) write(s string) {
+	p.b.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.col = utf8.RuneCountInString(s[i+1:])
+	} else {
+		p.col += utf8.RuneCountInString(s)
+	}
+}
+
+// breakLine requests a line break at the indentation level before the next token.
+func (p *printer) breakLine(level int) {
+	p.brk = level
+}
+
+// lineLevel returns the indentation level of the line of the next token.
+func (p *printer) lineLevel() int {
+	if p.brk >= 0 {
+		return p.brk
+	}
+	return p.level
+}
+
+func (p *printer) newline(level int, blank bool) {
+	p.brk = -1
+	p.afterLineComment = false
+	p.afterOwnLineComment = false
+	p.level = level
+	if p.b.Len() == 0 {
+		return
+	}
+
+	p.write(lo.Ternary(blank, "\n\n", "\n"))
+	p.write(strings.Repeat(p.indent, level))
+}
+
+// blankBefore is true if space before the next one contains an empty line.
+// A line comment contains the newline, so a single newline after it means an empty line.
+func (p *printer) blankBefore(space string) bool {
+	if p.b.Len() == 0 {
+		return false
+	}
+	if p.afterLineComment {
+		return strings.Contains(space, "\n")
+	}
+	return strings.Count(space, "\n") >= 2
+}
+
+// comments writes comments of tok.
+// A requested line break is kept after comments on their own lines, so a clause keyword still starts at a new line.
+func (p *printer) comments(tok token.Token) {
+	for _, c := range tok.Comments {
+		raw := strings.TrimRight(c.Raw, "\r\n")
+		if strings.Contains(c.Space, "\n") || p.afterLineComment || p.b.Len() == 0 {
+			brk := p.brk
+			p.newline(p.lineLevel(), p.blankBefore(c.Space))
+			p.write(raw)
+			p.afterOwnLineComment = !isLineComment(raw)
+			p.brk = brk
+		} else {
+			p.write(" " + raw)
+			p.afterOwnLineComment = false
+		}
+		p.afterLineComment = isLineComment(raw)
+		p.afterComment = true
+	}
+}
+
+func isLineComment(raw string) bool {
+	return !strings.HasPrefix(raw, "/*")
+}
+
+// token writes tok with its comments.
+func (p *printer) token(tok token.Token) {
+	p.comments(tok)
+
+	spacing := p.sp.next(tok)
+	switch {
+	case p.brk >= 0:
+		p.newline(p.brk, p.blankBefore(tok.Space))
+	case p.afterLineComment, p.afterOwnLineComment && strings.Contains(tok.Space, "\n"):
+		p.newline(p.level, p.blankBefore(tok.Space))
+	case p.b.Len() == 0:
+	case spacing != spacingNone || needsSeparation(p.prevRaw, tok.Raw),
+		p.afterComment && tok.Kind != ";" && tok.Kind != ",":
+		p.write(" ")
+	}
+
+	p.write(tok.Raw)
+	p.prevRaw = tok.Raw
+	p.afterLineComment = false
+	p.afterOwnLineComment = false
+	p.afterComment = false
+}
+
+// needsSeparation is true if prev and next are lexed differently without a whitespace.
+func needsSeparation(prev, next string) bool {
+	if prev == "" || next == "" {
+		return false
+	}
+
+	last, first := prev[len(prev)-1], next[0]
+	switch {
+	case char.IsIdentPart(last) && char.IsIdentPart(first):
+		return true
+	case last == '-' && first == '-',
+		last == '/' && (first == '*' || first == '/'),
+		prev == "@" && (first == '@' || char.IsIdentStart(first)):
+		return true
+	default:
+		return false
+	}
+}
+
+// measure returns the width of nodes in a line and whether nodes must be wrapped.
+func (p *printer) measure(nodes []fmtNode) (int, bool) {
+	sp := *p.sp
+	var width int
+	for i, tok := range flattenNodes(nodes) {
+		for _, c := range tok.Comments {
+			if strings.Contains(c.Space, "\n") || isLineComment(c.Raw) {
+				return width, true
+			}
+			width += 1 + utf8.RuneCountInString(c.Raw)
+		}
+
+		if spacing := sp.next(tok); i > 0 && spacing != spacingNone {
+			width++
+		}
+		if strings.Contains(tok.Raw, "\n") {
+			return width, true
+		}
+		width += utf8.RuneCountInString(tok.Raw)
+	}
+	return width, false
+}
+
+// fits is true if nodes fit in the current line.
+func (p *printer) fits(nodes []fmtNode) bool {
+	width, forced := p.measure(nodes)
+	col := p.col + 1
+	if p.brk >= 0 {
+		col = utf8.RuneCountInString(strings.Repeat(p.indent, p.brk))
+	}
+	return !forced && col+width <= p.width
+}
+
+func (p *printer) statement(tokens []token.Token) {
+	var terminator []token.Token
+	if last := len(tokens) - 1; tokens[last].Kind == ";" {
+		tokens, terminator = tokens[:last], tokens[last:]
+	}
+
+	nodes := buildNodes(tokens)
+	if isClauseStatement(nodes) {
+		p.query(nodes, 0)
+	} else {
+		p.plain(nodes, 0)
+	}
+
+	for _, tok := range terminator {
+		p.token(tok)
+	}
+	p.breakLine(0)
+}
+
+// isClauseStatement is true if nodes are a query or a DML statement.
+func isClauseStatement(nodes []fmtNode) bool {
+	for len(nodes) >= 2 && isHintNodes(nodes[:2]) {
+		nodes = nodes[2:]
+	}
+	if len(nodes) == 0 {
+		return false
+	}
+
+	first := nodes[0]
+	return first.group != nil && first.group.kind == fmtGroupQuery ||
+		isKeywordNode(first, "SELECT", "WITH", "FROM", "INSERT", "UPDATE", "DELETE")
+}
+
+// plain writes nodes of a statement which is not a query or a DML statement, e.g. DDL.
+// A query in the statement, e.g. `CREATE VIEW v AS SELECT ...`, is formatted as a query.
+func (p *printer) plain(nodes []fmtNode, level int) {
+	for i, n := range nodes {
+		if i > 0 && isKeywordNode(n, "SELECT") {
+			p.flat(nodes[:i])
+			p.breakLine(level)
+			p.query(nodes[i:], level)
+			return
+		}
+	}
+	p.flat(nodes)
+}
+
+// flat writes nodes without line breaks except in groups.
+func (p *printer) flat(nodes []fmtNode) {
+	for _, n := range nodes {
+		if n.group != nil {
+			p.group(n.group)
+		} else {
+			p.token(n.tok)
+		}
+	}
+}
+
+func (p *printer) group(g *fmtGroup) {
+	base := p.lineLevel()
+	closed := !g.close.Pos.Invalid()
+
+	switch {
+	case g.kind == fmtGroupQuery:
+		p.token(g.open)
+		p.breakLine(base + 1)
+		p.query(g.nodes, base+1)
+		if closed {
+			p.breakLine(base)
+		}
+	case g.kind == fmtGroupList && len(g.nodes) > 0 && !p.fits([]fmtNode{{group: g}}):
+		p.token(g.open)
+		p.wrap(g.nodes, base+1, commaSplits(g.nodes))
+		if closed {
+			p.breakLine(base)
+		}
+	default:
+		p.token(g.open)
+		p.flat(g.nodes)
+	}
+
+	if closed {
+		p.token(g.close)
+	}
+}
+
+// wrap writes nodes with line breaks at the head of nodes and each split.
+func (p *printer) wrap(nodes []fmtNode, level int, splits []int) {
+	prev := 0
+	for _, split := range append(splits, len(nodes)) {
+		p.breakLine(level)
+		p.flat(nodes[prev:split])
+		prev = split
+	}
+}
+
+// fmtClause is a clause of a query or a DML statement.
+type fmtClause struct {
+	keyword []fmtNode
+	body    []fmtNode
+}
+
+// query writes nodes of a query or a DML statement, each clause starts at a new line.
+func (p *printer) query(nodes []fmtNode, level int) {
+	prefix, clauses := splitClauses(nodes)
+	p.flat(prefix)
+
+	for i, c := range clauses {
+		if i > 0 || !isHintNodes(prefix) {
+			p.breakLine(level)
+		}
+		p.flat(c.keyword)
+
+		if len(c.body) == 0 {
+			continue
+		}
+
+		splits := clauseSplits(c)
+		width, forced := p.measure(c.body)
+		if len(splits) == 0 || !forced && p.col+1+width <= p.width {
+			p.flat(c.body)
+			continue
+		}
+		p.wrap(c.body, level+1, splits)
+	}
+}
+
+// splitClauses splits nodes to clauses. prefix is nodes before the first clause, e.g. statement hints.
+func splitClauses(nodes []fmtNode) ([]fmtNode, []fmtClause) {
+	var prefix []fmtNode
+	var clauses []fmtClause
+	isUpdate := false
+	for i := 0; i < len(nodes); {
+		first := len(clauses) == 0 && isHintNodes(nodes[:i])
+		if n := clauseKeywordLen(nodes, i, first, isUpdate); n > 0 {
+			if first && isKeywordNode(nodes[i], "UPDATE") {
+				isUpdate = true
+			}
+			clauses = append(clauses, fmtClause{keyword: nodes[i : i+n]})
+			i += n
+			continue
+		}
+
+		if len(clauses) == 0 {
+			prefix = append(prefix, nodes[i])
+		} else {
+			last := &clauses[len(clauses)-1]
+			last.body = append(last.body, nodes[i])
+		}
+		i++
+	}
+	return prefix, clauses
+}
+
+var fmtJoinModifiers = []string{"INNER", "LEFT", "RIGHT", "FULL", "CROSS", "OUTER", "HASH"}
+
+// clauseKeywordLen returns the number of nodes of the clause keyword at nodes[i], or 0 if it is not the head of a clause.
+// first is true if nodes[i] is the first node of the query except hints.
+func clauseKeywordLen(nodes []fmtNode, i int, first, isUpdate bool) int {
+	at := func(j int) fmtNode {
+		if j < 0 || j >= len(nodes) {
+			return fmtNode{tok: invalidToken}
+		}
+		return nodes[j]
+	}
+
+	// optional returns the number of consecutive nodes from j which match each of keywords.
+	optional := func(j int, keywords ...[]string) int {
+		for k, candidates := range keywords {
+			if !isKeywordNode(at(j+k), candidates...) {
+				return 0
+			}
+		}
+		return len(keywords)
+	}
+
+	// withHint extends n to include a hint after the keyword.
+	withHint := func(n int) int {
+		if i+n+1 < len(nodes) && isHintNodes(nodes[i+n:i+n+2]) {
+			return n + 2
+		}
+		return n
+	}
+
+	n := at(i)
+	switch {
+	case first && isKeywordNode(n, "WITH"):
+		return 1 + optional(i+1, []string{"RECURSIVE"})
+	case first && isKeywordNode(n, "INSERT"):
+		l := 1 + optional(i+1, []string{"OR"}, []string{"UPDATE", "IGNORE"})
+		return l + optional(i+l, []string{"INTO"})
+	case first && isKeywordNode(n, "DELETE"):
+		return 1 + optional(i+1, []string{"FROM"})
+	case first && isKeywordNode(n, "UPDATE"):
+		return 1
+	case isUpdate && isKeywordNode(n, "SET"):
+		return 1
+	case isKeywordNode(n, "SELECT"):
+		l := 1 + optional(i+1, []string{"ALL", "DISTINCT"})
+		return withHint(l + optional(i+l, []string{"AS"}, []string{"STRUCT", "VALUE"}))
+	case isKeywordNode(n, "FROM", "WHERE", "HAVING", "QUALIFY", "WINDOW", "LIMIT", "VALUES"):
+		return 1
+	case isKeywordNode(n, "OFFSET") && !isKeywordNode(at(i-1), "WITH"):
+		return 1
+	case isKeywordNode(n, "GROUP", "ORDER"):
+		return optional(i, []string{"GROUP", "ORDER"}, []string{"BY"})
+	case isKeywordNode(n, "UNION", "INTERSECT", "EXCEPT"):
+		return optional(i, []string{"UNION", "INTERSECT", "EXCEPT"}, []string{"ALL", "DISTINCT"})
+	case isKeywordNode(n, "THEN"):
+		return optional(i, []string{"THEN"}, []string{"RETURN"})
+	case isKeywordNode(n, fmtJoinModifiers...) && !isKeywordNode(at(i-1), fmtJoinModifiers...):
+		j := i
+		for isKeywordNode(at(j), fmtJoinModifiers...) {
+			j++
+		}
+		if !isKeywordNode(at(j), "JOIN") {
+			return 0
+		}
+		return withHint(j - i + 1)
+	case isKeywordNode(n, "JOIN") && !isKeywordNode(at(i-1), fmtJoinModifiers...):
+		return withHint(1)
+	default:
+		return 0
+	}
+}
+
+// clauseSplits returns indexes of the body of c where a line break is placed if the clause is wrapped.
+func clauseSplits(c fmtClause) []int {
+	switch {
+	case isKeywordNode(c.keyword[0], "WHERE", "HAVING", "QUALIFY"):
+		return logicalSplits(c.body, false)
+	case slices.ContainsFunc(c.keyword, func(n fmtNode) bool { return isKeywordNode(n, "JOIN") }):
+		return logicalSplits(c.body, true)
+	case isKeywordNode(c.keyword[0], "LIMIT", "OFFSET", "UNION", "INTERSECT", "EXCEPT", "DELETE", "UPDATE", "INSERT", "THEN"):
+		return nil
+	default:
+		return commaSplits(c.body)
+	}
+}
+
+// commaSplits returns indexes after commas.
+func commaSplits(nodes []fmtNode) []int {
+	var splits []int
+	for i, n := range nodes {
+		if i+1 < len(nodes) && n.group == nil && n.tok.Kind == "," {
+			splits = append(splits, i+1)
+		}
+	}
+	return splits
+}
+
+// logicalSplits returns indexes of AND and OR except AND of BETWEEN.
+// If join is true, it also returns indexes of ON and USING.
+func logicalSplits(nodes []fmtNode, join bool) []int {
+	var splits []int
+	var between bool
+	for i, n := range nodes {
+		switch {
+		case isKeywordNode(n, "BETWEEN"):
+			between = true
+		case between && isKeywordNode(n, "AND"):
+			between = false
+		case i > 0 && isKeywordNode(n, "AND", "OR"),
+			i > 0 && join && isKeywordNode(n, "ON", "USING"):
+			splits = append(splits, i)
+		}
+	}
+	return splits
+}
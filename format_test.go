@@ -0,0 +1,137 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestFormat(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []gsqlutils.FormatOption
+		want  string
+	}{
+		{
+			desc:  "empty",
+			input: "",
+			want:  "",
+		},
+		{
+			desc:  "clauses",
+			input: "select a, b from t where x = 1 and y = 2",
+			want: `select a, b
+from t
+where x = 1 and y = 2
+`,
+		},
+		{
+			desc:  "hints, joins and subqueries",
+			input: "@{OPTIMIZER_VERSION=7} SELECT s.Name, COUNT(*) AS cnt FROM Singers@{FORCE_INDEX=SingersByName} AS s JOIN@{JOIN_METHOD=HASH_JOIN} Albums AS a ON s.SingerId = a.SingerId WHERE s.SingerId IN (SELECT SingerId FROM Concerts) GROUP BY s.Name ORDER BY cnt DESC LIMIT 10",
+			opts:  []gsqlutils.FormatOption{gsqlutils.WithWidth(50)},
+			want: `@{OPTIMIZER_VERSION=7} SELECT
+  s.Name,
+  COUNT(*) AS cnt
+FROM Singers@{FORCE_INDEX=SingersByName} AS s
+JOIN @{JOIN_METHOD=HASH_JOIN}
+  Albums AS a
+  ON s.SingerId = a.SingerId
+WHERE s.SingerId IN (
+  SELECT SingerId
+  FROM Concerts
+)
+GROUP BY s.Name
+ORDER BY cnt DESC
+LIMIT 10
+`,
+		},
+		{
+			desc:  "CTE and set operation",
+			input: "WITH a AS (SELECT 1 AS x) SELECT x FROM a UNION ALL SELECT 2",
+			want: `WITH a AS (
+  SELECT 1 AS x
+)
+SELECT x
+FROM a
+UNION ALL
+SELECT 2
+`,
+		},
+		{
+			desc:  "comments",
+			input: "-- leading comment\nSELECT 1, -- first\n2 /* second */ FROM t; -- trailing\n\n/* next */\nSELECT 3;",
+			want: `-- leading comment
+SELECT
+  1, -- first
+  2 /* second */
+FROM t; -- trailing
+
+/* next */
+SELECT 3;
+`,
+		},
+		{
+			desc:  "DDL",
+			input: "CREATE TABLE Singers (SingerId INT64 NOT NULL, Name STRING(MAX)) PRIMARY KEY (SingerId)",
+			opts:  []gsqlutils.FormatOption{gsqlutils.WithWidth(40), gsqlutils.WithIndent("\t")},
+			want:  "CREATE TABLE Singers (\n\tSingerId INT64 NOT NULL,\n\tName STRING(MAX)\n) PRIMARY KEY (SingerId)\n",
+		},
+		{
+			desc:  "DML",
+			input: "INSERT INTO t (a, b) VALUES (1, -2), (3, 4) THEN RETURN a; UPDATE t SET a = 1 WHERE TRUE; DELETE FROM t WHERE a BETWEEN 1 AND 2",
+			want: `INSERT INTO t (a, b)
+VALUES (1, -2), (3, 4)
+THEN RETURN a;
+UPDATE t
+SET a = 1
+WHERE TRUE;
+DELETE FROM t
+WHERE a BETWEEN 1 AND 2
+`,
+		},
+		{
+			desc:  "comments before terminators and clause keywords",
+			input: "SELECT 1 /* x */, 2 /* y */ FROM t\n/* block */ WHERE TRUE /* z */;",
+			want: `SELECT 1 /* x */, 2 /* y */
+FROM t
+/* block */
+WHERE TRUE /* z */;
+`,
+		},
+		{
+			desc:  "unary minus",
+			input: "SELECT - 1, a - - b FROM t WHERE x = - 1",
+			want: `SELECT -1, a - -b
+FROM t
+WHERE x = -1
+`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.Format("", tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in formatted: (-want +got):\n%s", diff)
+			}
+
+			again, err := gsqlutils.Format("", got, tt.opts...)
+			if err != nil {
+				t.Fatalf("should success on formatted input, but failed: %v", err)
+			}
+			if diff := cmp.Diff(got, again); diff != "" {
+				t.Errorf("Format is not idempotent: (-first +second):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := gsqlutils.Format("", "SELECT 'unclosed"); err == nil {
+			t.Error("should fail, but success")
+		}
+	})
+}
@@ -1,9 +1,13 @@
 package gsqlutils_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/cloudspannerecosystem/memefish/token"
+
 	"github.com/apstndb/gsqlutils"
+	"github.com/apstndb/gsqlutils/tokenfilter"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -23,6 +27,12 @@ func TestSimpleSkipHints(t *testing.T) {
 		{desc: "DML statement hint and query parameters",
 			input: "@{OPTIMIZER_VERION=7}DELETE Singers@{FORCE_INDEX=_BASE_TABLE} WHERE FirstName = @first_name",
 			want:  "DELETE Singers WHERE FirstName = @first_name"},
+		{desc: "nested braces in hint", input: "@{a={b=(1)}, c=[2]}SELECT {x: 1}", want: "SELECT {x: 1}"},
+		{desc: "unary minus", input: "SELECT (-x), ARRAY[-1, - 2], STRUCT<a INT64>(-1)", want: "SELECT (-x), ARRAY[-1, -2], STRUCT<a INT64> (-1)"},
+		{desc: "unary minus after keywords and operators", input: "SELECT - 1, a - - b, - -c WHERE x = - 1", want: "SELECT -1, a - -b, - -c WHERE x = -1"},
+		{desc: "array literal and subscripts", input: "SELECT [1, 2], arr [OFFSET(0)], f(x) [0], ARRAY [1] FROM t WHERE x IN UNNEST([1])",
+			want: "SELECT [1, 2], arr[OFFSET(0)], f(x)[0], ARRAY[1] FROM t WHERE x IN UNNEST([1])"},
+		{desc: "system variable", input: "SELECT @@statement_timeout, @@ x", want: "SELECT @@statement_timeout, @@x"},
 	} {
 		t.Run(test.desc, func(t *testing.T) {
 			// got, err := internal.StripComments("", test.input)
@@ -55,6 +65,10 @@ func TestSimpleStripComments(t *testing.T) {
 		{desc: "DML statement hint and query parameters",
 			input: "@{OPTIMIZER_VERION=7} DELETE Singers@{FORCE_INDEX=_BASE_TABLE} WHERE FirstName = @first_name",
 			want:  "@{OPTIMIZER_VERION=7} DELETE Singers@{FORCE_INDEX=_BASE_TABLE} WHERE FirstName = @first_name"},
+		{desc: "nested braces in hint", input: "@{a = {b = 1}} SELECT 1", want: "@{a={b=1}} SELECT 1"},
+		{desc: "unary minus", input: "SELECT (-x), ARRAY[-1, - 2], STRUCT<a INT64>(-1)", want: "SELECT (-x), ARRAY[-1, -2], STRUCT<a INT64> (-1)"},
+		{desc: "unary minus after keywords and operators", input: "SELECT - 1, a - - b, - -c WHERE x = - 1", want: "SELECT -1, a - -b, - -c WHERE x = -1"},
+		{desc: "system variable", input: "SELECT @@statement_timeout, @@ x", want: "SELECT @@statement_timeout, @@x"},
 	} {
 		t.Run(test.desc, func(t *testing.T) {
 			// got, err := internal.StripComments("", test.input)
@@ -198,7 +212,13 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantErr: &gsqlutils.ErrLexerStatus{WaitingString: "*/"},
+			wantErr: &gsqlutils.ErrLexerStatus{
+				WaitingString: "*/",
+				Location:      gsqlutils.Location{Pos: 7, Line: 0, Column: 7, RuneColumn: 7, UTF16Column: 7},
+				Open: []gsqlutils.OpenConstruct{
+					{Kind: gsqlutils.ConstructComment, Opening: "/*", Closing: "*/", Location: gsqlutils.Location{Pos: 7, Line: 0, Column: 7, RuneColumn: 7, UTF16Column: 7}},
+				},
+			},
 		},
 		{
 			desc:  "non-closed triple double quoted",
@@ -210,7 +230,13 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantErr: &gsqlutils.ErrLexerStatus{WaitingString: `"""`},
+			wantErr: &gsqlutils.ErrLexerStatus{
+				WaitingString: `"""`,
+				Location:      gsqlutils.Location{Pos: 7, Line: 0, Column: 7, RuneColumn: 7, UTF16Column: 7},
+				Open: []gsqlutils.OpenConstruct{
+					{Kind: gsqlutils.ConstructString, Opening: `"""`, Closing: `"""`, Location: gsqlutils.Location{Pos: 7, Line: 0, Column: 7, RuneColumn: 7, UTF16Column: 7}},
+				},
+			},
 		},
 		{
 			desc:  "closed triple double quoted",
@@ -233,7 +259,37 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 					Terminator: terminatorUndefined,
 				},
 			},
-			wantErr: &gsqlutils.ErrLexerStatus{WaitingString: `'''`},
+			wantErr: &gsqlutils.ErrLexerStatus{
+				WaitingString: `'''`,
+				Location:      gsqlutils.Location{Pos: 7, Line: 0, Column: 7, RuneColumn: 7, UTF16Column: 7},
+				Open: []gsqlutils.OpenConstruct{
+					{Kind: gsqlutils.ConstructString, Opening: `'''`, Closing: `'''`, Location: gsqlutils.Location{Pos: 7, Line: 0, Column: 7, RuneColumn: 7, UTF16Column: 7}},
+				},
+			},
+		},
+		{
+			desc:  "non-closed comment after terminator",
+			input: "SELECT 1;\n/*123",
+			want: []gsqlutils.RawStatement{
+				{
+					Statement:  "SELECT 1",
+					End:        9,
+					Terminator: terminatorHorizontal,
+				},
+				{
+					Statement:  "/*123",
+					Pos:        10,
+					End:        15,
+					Terminator: terminatorUndefined,
+				},
+			},
+			wantErr: &gsqlutils.ErrLexerStatus{
+				WaitingString: "*/",
+				Location:      gsqlutils.Location{Pos: 10, Line: 1, Column: 0, RuneColumn: 0, UTF16Column: 0},
+				Open: []gsqlutils.OpenConstruct{
+					{Kind: gsqlutils.ConstructComment, Opening: "/*", Closing: "*/", Location: gsqlutils.Location{Pos: 10, Line: 1, Column: 0, RuneColumn: 0, UTF16Column: 0}},
+				},
+			},
 		},
 		{
 			desc:  "closed triple single quoted",
@@ -265,3 +321,63 @@ func TestSeparateInputPreserveCommentsWithStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestFirstNonHintToken(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  string
+	}{
+		{input: "SELECT 1", want: "SELECT"},
+		{input: "@{OPTIMIZER_VERSION=7} SELECT 1", want: "SELECT"},
+		{input: "@{a={b=1}} @{c=()} WITH t AS (SELECT 1) SELECT * FROM t", want: "WITH"},
+	} {
+		tok, err := gsqlutils.FirstNonHintToken("", tt.input)
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+		if tok.Raw != tt.want {
+			t.Errorf("%v: want %v, but got %v", tt.input, tt.want, tok.Raw)
+		}
+	}
+}
+
+func TestHintError(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		input   string
+		wantPos token.Pos
+		wantAt  token.Pos
+		wantMsg string
+	}{
+		{desc: "unclosed", input: "@{a={b=1} SELECT 1", wantPos: 18, wantAt: 0, wantMsg: "test.sql:1:19: unclosed hint, hint started at 1:1"},
+		{desc: "semicolon in hint", input: "SELECT 1;\n@{a=1\nSELECT 1; SELECT 2", wantPos: 24, wantAt: 10, wantMsg: "test.sql:3:9: unclosed hint, hint started at 2:1"},
+		{desc: "mismatched closing", input: "@{a=(1}} SELECT 1", wantPos: 6, wantAt: 0, wantMsg: "test.sql:1:7: unexpected } in hint, want ), hint started at 1:1"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			for name, f := range map[string]func(string, string) (string, error){
+				"SimpleSkipHints": gsqlutils.SimpleSkipHints,
+				"FirstNonHintToken": func(filepath, s string) (string, error) {
+					tok, err := gsqlutils.FirstNonHintToken(filepath, s)
+					return tok.Raw, err
+				},
+			} {
+				if name == "FirstNonHintToken" && tt.wantAt > 0 {
+					// The first non-hint token is before the hint.
+					continue
+				}
+
+				_, err := f("test.sql", tt.input)
+				var hintErr *tokenfilter.HintError
+				if !errors.As(err, &hintErr) {
+					t.Fatalf("%v: want HintError, but got %v", name, err)
+				}
+				if hintErr.Tok.Pos != tt.wantPos || hintErr.At.Pos != tt.wantAt {
+					t.Errorf("%v: want error at %v in hint at %v, but got %v", name, tt.wantPos, tt.wantAt, err)
+				}
+				if diff := cmp.Diff(tt.wantMsg, hintErr.Error()); diff != "" {
+					t.Errorf("%v: difference in message: (-want +got):\n%s", name, diff)
+				}
+			}
+		})
+	}
+}
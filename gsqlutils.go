@@ -21,17 +21,21 @@ type RawStatement struct {
 	Terminator string
 }
 
+// NewLexerSeq lexes s as LexerSeq.
+// filename can be empty, it is only used in error message.
 func NewLexerSeq(filename, s string) iter.Seq2[token.Token, error] {
 	return LexerSeq(newLexer(filename, s))
 }
 
 // LexerSeq converts memefish.Lexer to iter.Seq2 with error.
 // If it reaches to EOF, it stops without error.
+// Errors are classified as *LexerError if possible, so they can't be type-asserted to *memefish.Error.
+// Use errors.As to get *memefish.Error, it works for both classified and unclassified errors.
 func LexerSeq(lexer *memefish.Lexer) iter.Seq2[token.Token, error] {
 	return func(yield func(token.Token, error) bool) {
 		for {
 			if err := lexer.NextToken(); err != nil {
-				_ = yield(lexer.Token, err)
+				_ = yield(lexer.Token, ClassifyLexerError(lexer.File.Buffer, lexer.Token.Pos, err))
 				return
 			}
 
@@ -57,67 +61,48 @@ func (stmt *RawStatement) StripComments() (RawStatement, error) {
 	}, err
 }
 
+// ErrLexerStatus is an error which means the input is incomplete and can be continued by the following input.
 type ErrLexerStatus struct {
+	// WaitingString is the string to close the innermost unclosed construct.
 	WaitingString string
+
+	// Location is the head of the innermost unclosed construct.
+	Location Location
+
+	// Open is the stack of unclosed constructs in the statement, the outermost first.
+	// The last one is the construct which the lexer is waiting to be closed.
+	Open []OpenConstruct
 }
 
 func (e *ErrLexerStatus) Error() string {
 	return fmt.Sprintf("lexer error with waiting: %v", e.WaitingString)
 }
 
+// SeparateInputPreserveCommentsWithStatus separates an input string to statements at terminating semicolons.
+// It is same as SeparateInput without options.
 func SeparateInputPreserveCommentsWithStatus(filepath, s string) ([]RawStatement, error) {
-	lexer := newLexer(filepath, s)
-
-	var results []RawStatement
-	var pos token.Pos
-outer:
-	for tok, err := range LexerSeq(lexer) {
-		if err != nil {
-			if err, ok := lo.ErrorsAs[*memefish.Error](err); ok {
-				results = append(results, RawStatement{Pos: pos, End: err.Position.End, Statement: lexer.Buffer[pos:err.Position.End]})
-				return results, toErrLexerStatus(err, lexer.Buffer[tok.Pos:])
-			}
-			return results, err
-		}
-
-		// renew pos to first comment or first token of a statement.
-		if pos.Invalid() {
-			tokenComment, ok := lo.First(tok.Comments)
-			pos = lo.Ternary(ok, tokenComment.Pos, tok.Pos)
-		}
+	return SeparateInput(filepath, s)
+}
 
-		switch tok.Kind {
-		case token.TokenEOF:
-			// If pos:tok.Pos is not empty, add remaining part of buffer to result.
-			if pos != tok.Pos {
-				results = append(results, RawStatement{Statement: s[pos:tok.Pos], Pos: pos, End: tok.Pos})
-			}
-			// no need to continue
-			break outer
-		case ";":
-			results = append(results, RawStatement{Statement: s[pos:tok.Pos], Pos: pos, End: tok.End, Terminator: ";"})
-			pos = token.InvalidPos
-		default:
-		}
+// toErrLexerStatus converts err to ErrLexerStatus if the input can be continued.
+// s is the whole input, and tokens are tokens of the statement before the error.
+func toErrLexerStatus(err error, s string, tokens []token.Token) error {
+	lerr, ok := lo.ErrorsAs[*LexerError](err)
+	if !ok {
+		return err
 	}
-	return results, nil
-}
 
-const errMessageUnclosedTripleQuotedStringLiteral = `unclosed triple-quoted string literal`
-const errMessageUnclosedComment = `unclosed comment`
-
-// NOTE: memefish.Error.Message can be changed.
-func toErrLexerStatus(err *memefish.Error, head string) error {
-	switch {
-	case err.Message == errMessageUnclosedTripleQuotedStringLiteral && strings.HasPrefix(head, `"""`):
-		return &ErrLexerStatus{WaitingString: `"""`}
-	case err.Message == errMessageUnclosedTripleQuotedStringLiteral:
-		return &ErrLexerStatus{WaitingString: `'''`}
-	case err.Message == errMessageUnclosedComment:
-		return &ErrLexerStatus{WaitingString: `*/`}
-	default:
+	newIndex := lazyPositionIndex(s)
+	construct, ok := lexerConstruct(lerr, s, newIndex)
+	if !ok {
 		return err
 	}
+
+	return &ErrLexerStatus{
+		WaitingString: construct.Closing,
+		Location:      construct.Location,
+		Open:          append(openBrackets(tokens, newIndex), construct),
+	}
 }
 
 // StripComments strips comments in an input string without parsing but preserving whitespaces.
@@ -189,12 +174,12 @@ func StripComments(filepath, s string) (string, error) {
 }
 
 // FirstNonHintToken returns the first non-hint token.
+// Hints can contain nested braces, and a malformed or unclosed hint is an error with its position.
 // filepath can be empty, it is only used in error message.
-// Note: Currently, this function doesn't take care about nested curly brace in a hint.
 func FirstNonHintToken(filepath, s string) (token.Token, error) {
 	lexer := newLexer(filepath, s)
 
-	next, stop := iter.Pull2(tokenfilter.StripHints(LexerSeq(lexer)))
+	next, stop := iter.Pull2(tokenfilter.LocateHintErrors(lexer.File, tokenfilter.StripHints(LexerSeq(lexer))))
 	defer stop()
 
 	tok, err, _ := next()
@@ -207,9 +192,10 @@ func FirstNonHintToken(filepath, s string) (token.Token, error) {
 
 // SimpleSkipHints strips hints in an input string without parsing.
 // It don't preserve any hints and comments and whitespaces. All tokens are separated with a single whitespace.
+// Unary minus and system variables are joined with their operands, e.g. `-1` and `@@statement_timeout`, as Format does.
 // filepath can be empty, it is only used in error message.
 func SimpleSkipHints(filepath, s string) (string, error) {
-	s, err := tryUnlexTokenSeq(true, tokenfilter.StripHints(NewLexerSeq(filepath, s)))
+	s, err := tryUnlexTokenSeq(true, newStripHintsSeq(filepath, s))
 	if err != nil {
 		return s, fmt.Errorf("error on SimpleSkipHints, err: %w", err)
 	}
@@ -226,25 +212,22 @@ func prevKind[N internal.Integer](tokens []token.Token, prevNth N) token.TokenKi
 	return nthToken(tokens, -prevNth).Kind
 }
 
-type tokenList []token.Token
-
-func (t tokenList) prevKind(prevNth int) token.TokenKind {
-	return prevKind(t, prevNth)
+// tokenSliceSeq converts tokens to iter.Seq2 without errors.
+func tokenSliceSeq(tokens []token.Token) iter.Seq2[token.Token, error] {
+	return func(yield func(token.Token, error) bool) {
+		for _, tok := range tokens {
+			if !yield(tok, nil) {
+				return
+			}
+		}
+	}
 }
 
 // tryUnlexTokenSeqSimple convert seq to string, it ignores whitespaces and comments.
 // Token are separated with a single whitespace, except when two tokens are consecutive with no whitespaces in between.
 func tryUnlexTokenSeq(newlineOnSemicolon bool, seq iter.Seq2[token.Token, error]) (string, error) {
-	tokens := tokenList(nil)
-
 	var b strings.Builder
-	prev := token.Token{Pos: token.InvalidPos, End: token.InvalidPos}
-
-	// Count "{" level in hint
-	inHintLevel := 0
-
-	// Count "<" level in compound type
-	compoundTypeLevel := 0
+	sp := newSpacer()
 	for tok, err := range seq {
 		if err != nil {
 			return b.String(), err
@@ -254,83 +237,21 @@ func tryUnlexTokenSeq(newlineOnSemicolon bool, seq iter.Seq2[token.Token, error]
 			break
 		}
 
-		if (compoundTypeLevel > 0 || prev.Kind == "ARRAY" || prev.Kind == "STRUCT") && tok.Kind == "<" {
-			compoundTypeLevel++
-		}
-
-		if (prev.Kind == "@" || inHintLevel > 0) && tok.Kind == "{" {
-			inHintLevel++
-		}
-
-		if b.Len() > 0 {
-			switch {
-			// first token after semicolon
-			case prev.Kind == ";":
-				b.WriteRune(lo.Ternary(newlineOnSemicolon, '\n', ' '))
-			// after open or dot
-			case internal.OneOf(prev.Kind, "(", "{", "[", "."),
-				// before close or dot, comma, colon
-				internal.OneOf(tok.Kind, ")", "}", "]", ".", ",", ":", ";"),
-				// hint
-				tok.Kind == "@" && internal.OneOf(prev.Kind, ")", token.TokenIdent),
-				prev.Kind == "@" && tok.Kind == "{",
-				internal.OneOf(prev.Kind, "@") && internal.OneOf(tok.Kind, "{"),
-				inHintLevel > 0 && prev.Kind == "=",
-				inHintLevel > 0 && tok.Kind == "=",
-
-				// '<' & '>' in compound types
-				internal.OneOf(prev.Kind, "STRUCT", "ARRAY") && internal.OneOf(tok.Kind, "<", "<>"),
-				compoundTypeLevel > 0 && prev.Kind == "<",
-				compoundTypeLevel > 0 && internal.OneOf(tok.Kind, ">", ">>"),
-
-				// function like keyword
-				tok.Kind == "(" && internal.OneOf(prev.Kind, "UNNEST", "WITH", "STRUCT", "ARRAY", "CAST"),
-
-				// subscript expression
-				tok.Kind == "[",
-
-				// unary minus
-
-				prev.Kind == "-" && internal.OneOf(tokens.prevKind(-2), "[", "{", "<", "(", ",", token.TokenBad),
-
-				// "identifier(" can be function calls, it is natural not to be separated by whitespace, preserve original.
-				// Note: STORING () should be separated by whitespaces
-				tok.Kind == "(" && prev.Kind == token.TokenIdent &&
-					!prev.IsKeywordLike("STORING") &&
-					tok.Pos == prev.End:
-				break
-			default:
-				b.WriteRune(' ')
-			}
-		}
-
-		if compoundTypeLevel > 0 {
-			switch {
-			case tok.Kind == ">":
-				compoundTypeLevel -= 1
-			case tok.Kind == ">>":
-				compoundTypeLevel -= 2
-			}
-		}
-
-		if inHintLevel > 0 && tok.Kind == "}" {
-			inHintLevel--
+		switch sp.next(tok) {
+		case spacingStatement:
+			b.WriteRune(lo.Ternary(newlineOnSemicolon, '\n', ' '))
+		case spacingSpace:
+			b.WriteRune(' ')
 		}
 
 		b.WriteString(tok.Raw)
-
-		prev = tok
-
-		if tok.Kind == ";" {
-			tokens = tokenList(nil)
-		}
-		tokens = append(tokens, tok)
 	}
 	return b.String(), nil
 }
 
 // SimpleStripComments strips comments in an input string without parsing.
 // It don't preserve whitespaces. All tokens are separated with a single whitespace.
+// Unary minus and system variables are joined with their operands, e.g. `-1` and `@@statement_timeout`, as Format does.
 // filepath can be empty, it is only used in error message.
 //
 // [terminating semicolons]: https://cloud.google.com/spanner/docs/reference/standard-sql/lexical#terminating_semicolons
@@ -342,6 +263,18 @@ func SimpleStripComments(filepath, s string) (string, error) {
 	return s, nil
 }
 
+// newStripHintsSeq lexes s and strips hints, and positions of hint errors are resolved in s.
+func newStripHintsSeq(filepath, s string) iter.Seq2[token.Token, error] {
+	lexer := newLexer(filepath, s)
+	return tokenfilter.LocateHintErrors(lexer.File, tokenfilter.StripHints(LexerSeq(lexer)))
+}
+
+// newAnnotateHintsSeq lexes s and annotates hints, and positions of hint errors are resolved in s.
+func newAnnotateHintsSeq(filepath, s string) iter.Seq2[tokenfilter.HintToken, error] {
+	lexer := newLexer(filepath, s)
+	return tokenfilter.LocateHintErrors(lexer.File, tokenfilter.AnnotateHints(LexerSeq(lexer)))
+}
+
 func newLexer(filepath string, s string) *memefish.Lexer {
 	return &memefish.Lexer{
 		File: &token.File{
@@ -0,0 +1,307 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HintValueKind is a kind of values of a hint.
+type HintValueKind int
+
+const (
+	HintValueInvalid HintValueKind = iota
+
+	// HintValueBool is TRUE or FALSE.
+	HintValueBool
+
+	// HintValueInt is an integer in [Min, Max], or one of Values.
+	HintValueInt
+
+	// HintValueEnum is one of Values.
+	HintValueEnum
+
+	// HintValueIdentifier is an identifier, e.g. an index name.
+	HintValueIdentifier
+)
+
+func (k HintValueKind) String() string {
+	switch k {
+	case HintValueBool:
+		return "Bool"
+	case HintValueInt:
+		return "Int"
+	case HintValueEnum:
+		return "Enum"
+	case HintValueIdentifier:
+		return "Identifier"
+	case HintValueInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(k))
+	}
+}
+
+// HintSpec is a specification of a hint key.
+type HintSpec struct {
+	// Key is the key, which is compared case-insensitively.
+	Key string
+
+	// Scopes are valid scopes. HintScopeTable also allows HintScopeIndex.
+	Scopes []HintScope
+
+	Kind HintValueKind
+
+	// Values are valid values of HintValueEnum, or valid non-integer values of HintValueInt. They are compared case-insensitively.
+	Values []string
+
+	// Min and Max are the range of HintValueInt.
+	Min, Max int64
+}
+
+// HintCatalog is a catalog of known hints keyed by keys in upper case.
+type HintCatalog map[string]HintSpec
+
+// NewHintCatalog creates a catalog of specs.
+func NewHintCatalog(specs ...HintSpec) HintCatalog {
+	c := make(HintCatalog, len(specs))
+	for _, spec := range specs {
+		c[strings.ToUpper(spec.Key)] = spec
+	}
+	return c
+}
+
+var spannerHintSpecs = []HintSpec{
+	// Statement hints
+	{Key: "USE_ADDITIONAL_PARALLELISM", Scopes: []HintScope{HintScopeStatement}, Kind: HintValueBool},
+	{Key: "OPTIMIZER_VERSION", Scopes: []HintScope{HintScopeStatement}, Kind: HintValueInt, Min: 1, Max: 1<<31 - 1, Values: []string{"latest_version", "default_version"}},
+	{Key: "OPTIMIZER_STATISTICS_PACKAGE", Scopes: []HintScope{HintScopeStatement}, Kind: HintValueIdentifier},
+	{Key: "ALLOW_DISTRIBUTED_MERGE", Scopes: []HintScope{HintScopeStatement}, Kind: HintValueBool},
+	{Key: "LOCK_SCANNED_RANGES", Scopes: []HintScope{HintScopeStatement}, Kind: HintValueEnum, Values: []string{"exclusive", "shared"}},
+	{Key: "EXECUTION_METHOD", Scopes: []HintScope{HintScopeStatement}, Kind: HintValueEnum, Values: []string{"DEFAULT", "BATCH", "ROW"}},
+	{Key: "USE_UNENFORCED_FOREIGN_KEY", Scopes: []HintScope{HintScopeStatement}, Kind: HintValueBool},
+	{Key: "ALLOW_TIMESTAMP_PREDICATE_PUSHDOWN", Scopes: []HintScope{HintScopeStatement}, Kind: HintValueBool},
+
+	// Statement and table hints
+	{Key: "SCAN_METHOD", Scopes: []HintScope{HintScopeStatement, HintScopeTable}, Kind: HintValueEnum, Values: []string{"AUTO", "BATCH", "ROW"}},
+
+	// Table hints
+	{Key: "FORCE_INDEX", Scopes: []HintScope{HintScopeTable}, Kind: HintValueIdentifier},
+	{Key: "GROUPBY_SCAN_OPTIMIZATION", Scopes: []HintScope{HintScopeTable}, Kind: HintValueBool},
+	{Key: "INDEX_STRATEGY", Scopes: []HintScope{HintScopeTable}, Kind: HintValueEnum, Values: []string{"FORCE_INDEX_UNION"}},
+	{Key: "SEEKABLE_KEY_SIZE", Scopes: []HintScope{HintScopeTable}, Kind: HintValueInt, Min: 0, Max: 16},
+
+	// Statement and join hints
+	{Key: "FORCE_JOIN_ORDER", Scopes: []HintScope{HintScopeStatement, HintScopeJoin}, Kind: HintValueBool},
+
+	// Join and IN subquery hints
+	{Key: "JOIN_METHOD", Scopes: []HintScope{HintScopeJoin, HintScopeSubquery}, Kind: HintValueEnum, Values: []string{"HASH_JOIN", "APPLY_JOIN", "MERGE_JOIN", "PUSH_BROADCAST_HASH_JOIN"}},
+	{Key: "HASH_JOIN_BUILD_SIDE", Scopes: []HintScope{HintScopeJoin, HintScopeSubquery}, Kind: HintValueEnum, Values: []string{"BUILD_LEFT", "BUILD_RIGHT"}},
+	{Key: "BATCH_MODE", Scopes: []HintScope{HintScopeJoin, HintScopeSubquery}, Kind: HintValueBool},
+	{Key: "HASH_JOIN_EXECUTION", Scopes: []HintScope{HintScopeJoin, HintScopeSubquery}, Kind: HintValueEnum, Values: []string{"MULTI_PASS", "ONE_PASS"}},
+}
+
+// SpannerHintCatalog returns a catalog of hints documented for Cloud Spanner GoogleSQL.
+// The returned catalog is a copy, so callers can add their own specs.
+func SpannerHintCatalog() HintCatalog {
+	return NewHintCatalog(spannerHintSpecs...)
+}
+
+// HintDiagnosticKind is a kind of problems of a hint record.
+type HintDiagnosticKind int
+
+const (
+	HintDiagnosticInvalid HintDiagnosticKind = iota
+
+	// HintDiagnosticUnknownKey is a key which is not in the catalog.
+	HintDiagnosticUnknownKey
+
+	// HintDiagnosticWrongScope is a key which is not valid in the scope of the hint.
+	HintDiagnosticWrongScope
+
+	// HintDiagnosticInvalidValue is a value which is not valid for the key.
+	HintDiagnosticInvalidValue
+
+	// HintDiagnosticDuplicateKey is a key which appears twice in a hint.
+	HintDiagnosticDuplicateKey
+
+	// HintDiagnosticUnknownScope is a hint whose scope can't be determined.
+	HintDiagnosticUnknownScope
+)
+
+func (k HintDiagnosticKind) String() string {
+	switch k {
+	case HintDiagnosticUnknownKey:
+		return "UnknownKey"
+	case HintDiagnosticWrongScope:
+		return "WrongScope"
+	case HintDiagnosticInvalidValue:
+		return "InvalidValue"
+	case HintDiagnosticDuplicateKey:
+		return "DuplicateKey"
+	case HintDiagnosticUnknownScope:
+		return "UnknownScope"
+	case HintDiagnosticInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(k))
+	}
+}
+
+// HintDiagnostic is a problem of a hint record.
+type HintDiagnostic struct {
+	Kind    HintDiagnosticKind
+	Message string
+
+	// Range is the range of the key, or the value for HintDiagnosticInvalidValue, or the hint for HintDiagnosticUnknownScope.
+	Range Range
+}
+
+func (d HintDiagnostic) String() string {
+	return fmt.Sprintf("%v: %v", d.Range.Start, d.Message)
+}
+
+// ValidateHints extracts hints in s and validates them with catalog.
+// filepath can be empty, it is only used in error message.
+func ValidateHints(filepath, s string, catalog HintCatalog) ([]HintDiagnostic, error) {
+	hints, err := ExtractHints(filepath, s)
+	if err != nil {
+		return nil, fmt.Errorf("error on ValidateHints, err: %w", err)
+	}
+	return catalog.Validate(hints), nil
+}
+
+// Validate validates hints and returns diagnostics in order of positions.
+// Keys qualified with `spanner.` are validated without the qualifier, and keys with other qualifiers are ignored.
+func (c HintCatalog) Validate(hints []Hint) []HintDiagnostic {
+	var result []HintDiagnostic
+	for _, h := range hints {
+		if h.Scope == HintScopeInvalid {
+			result = append(result, HintDiagnostic{
+				Kind:    HintDiagnosticUnknownScope,
+				Message: "hint is not attached to a statement, a table, a join or a subquery",
+				Range:   h.Range,
+			})
+		}
+
+		seen := make(map[string]bool)
+		for _, r := range h.Records {
+			key := strings.ToUpper(r.Key)
+			if qualifier, name, ok := strings.Cut(key, "."); ok {
+				if qualifier != "SPANNER" {
+					continue
+				}
+				key = name
+			}
+
+			if seen[key] {
+				result = append(result, HintDiagnostic{Kind: HintDiagnosticDuplicateKey, Message: fmt.Sprintf("duplicate hint key %v", r.Key), Range: r.KeyRange})
+			}
+			seen[key] = true
+
+			spec, ok := c[key]
+			if !ok {
+				msg := fmt.Sprintf("unknown hint key %v", r.Key)
+				if suggestion, ok := c.suggest(key); ok {
+					msg += fmt.Sprintf(", did you mean %v?", suggestion)
+				}
+				result = append(result, HintDiagnostic{Kind: HintDiagnosticUnknownKey, Message: msg, Range: r.KeyRange})
+				continue
+			}
+
+			if h.Scope != HintScopeInvalid && !spec.validScope(h.Scope) {
+				result = append(result, HintDiagnostic{
+					Kind:    HintDiagnosticWrongScope,
+					Message: fmt.Sprintf("hint key %v is not valid in %v hint", r.Key, strings.ToLower(h.Scope.String())),
+					Range:   r.KeyRange,
+				})
+			}
+
+			if !spec.validValue(r.Value) {
+				result = append(result, HintDiagnostic{
+					Kind:    HintDiagnosticInvalidValue,
+					Message: fmt.Sprintf("invalid value %v for hint key %v, want %v", r.Value, r.Key, spec.describeValue()),
+					Range:   r.ValueRange,
+				})
+			}
+		}
+	}
+	return result
+}
+
+func (spec HintSpec) validScope(scope HintScope) bool {
+	if scope == HintScopeIndex {
+		scope = HintScopeTable
+	}
+	return slices.Contains(spec.Scopes, scope)
+}
+
+func (spec HintSpec) validValue(value string) bool {
+	if slices.ContainsFunc(spec.Values, func(v string) bool { return strings.EqualFold(v, value) }) {
+		return true
+	}
+
+	switch spec.Kind {
+	case HintValueBool:
+		return strings.EqualFold(value, "TRUE") || strings.EqualFold(value, "FALSE")
+	case HintValueInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		return err == nil && spec.Min <= n && n <= spec.Max
+	case HintValueIdentifier:
+		_, err := UnquoteIdentifier(value)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func (spec HintSpec) describeValue() string {
+	switch spec.Kind {
+	case HintValueBool:
+		return "TRUE or FALSE"
+	case HintValueInt:
+		desc := fmt.Sprintf("an integer in [%v, %v]", spec.Min, spec.Max)
+		if len(spec.Values) > 0 {
+			desc += " or " + strings.Join(spec.Values, "|")
+		}
+		return desc
+	case HintValueIdentifier:
+		return "an identifier"
+	default:
+		return strings.Join(spec.Values, "|")
+	}
+}
+
+// suggest returns the known key nearest to key, if it is likely a misspelling.
+func (c HintCatalog) suggest(key string) (string, bool) {
+	best, bestDistance := "", 3
+	for _, known := range slices.Sorted(maps.Keys(c)) {
+		if d := editDistance(key, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance is the Levenshtein distance between a and b in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
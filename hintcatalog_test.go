@@ -0,0 +1,99 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestValidateHints(t *testing.T) {
+	type diagnostic struct {
+		Kind    string
+		Message string
+		Range   string
+	}
+
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []diagnostic
+	}{
+		{
+			desc:  "valid hints",
+			input: "@{OPTIMIZER_VERSION=7, lock_scanned_ranges=Exclusive, spanner.use_additional_parallelism=true} SELECT * FROM Singers@{FORCE_INDEX=_BASE_TABLE} JOIN@{JOIN_METHOD=HASH_JOIN} Albums@{SCAN_METHOD=BATCH} USING (SingerId) WHERE x IN @{JOIN_METHOD=APPLY_JOIN} (SELECT 1)",
+			want:  nil,
+		},
+		{
+			desc:  "misspelled key",
+			input: "@{OPTIMIZER_VERION=7}DELETE Singers@{FORCE_INDEX=_BASE_TABLE} WHERE TRUE",
+			want: []diagnostic{
+				{Kind: "UnknownKey", Message: "unknown hint key OPTIMIZER_VERION, did you mean OPTIMIZER_VERSION?", Range: "1:3-1:19"},
+			},
+		},
+		{
+			desc:  "wrong scopes",
+			input: "@{FORCE_INDEX=idx} SELECT * FROM Singers@{JOIN_METHOD=HASH_JOIN}",
+			want: []diagnostic{
+				{Kind: "WrongScope", Message: "hint key FORCE_INDEX is not valid in statement hint", Range: "1:3-1:14"},
+				{Kind: "WrongScope", Message: "hint key JOIN_METHOD is not valid in table hint", Range: "1:43-1:54"},
+			},
+		},
+		{
+			desc:  "invalid values",
+			input: "@{OPTIMIZER_VERSION=latest, LOCK_SCANNED_RANGES=none} SELECT * FROM a JOIN @{JOIN_METHOD=NESTED_LOOP_JOIN, BATCH_MODE=1} b ON TRUE",
+			want: []diagnostic{
+				{Kind: "InvalidValue", Message: "invalid value latest for hint key OPTIMIZER_VERSION, want an integer in [1, 2147483647] or latest_version|default_version", Range: "1:21-1:27"},
+				{Kind: "InvalidValue", Message: "invalid value none for hint key LOCK_SCANNED_RANGES, want exclusive|shared", Range: "1:49-1:53"},
+				{Kind: "InvalidValue", Message: "invalid value NESTED_LOOP_JOIN for hint key JOIN_METHOD, want HASH_JOIN|APPLY_JOIN|MERGE_JOIN|PUSH_BROADCAST_HASH_JOIN", Range: "1:90-1:106"},
+				{Kind: "InvalidValue", Message: "invalid value 1 for hint key BATCH_MODE, want TRUE or FALSE", Range: "1:119-1:120"},
+			},
+		},
+		{
+			desc:  "duplicate and unknown keys",
+			input: "@{USE_ADDITIONAL_PARALLELISM=TRUE, use_additional_parallelism=FALSE, no_such_hint=1, other.hint=1} SELECT 1",
+			want: []diagnostic{
+				{Kind: "DuplicateKey", Message: "duplicate hint key use_additional_parallelism", Range: "1:36-1:62"},
+				{Kind: "UnknownKey", Message: "unknown hint key no_such_hint", Range: "1:70-1:82"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			diagnostics, err := gsqlutils.ValidateHints("", tt.input, gsqlutils.SpannerHintCatalog())
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+
+			var got []diagnostic
+			for _, d := range diagnostics {
+				got = append(got, diagnostic{
+					Kind:    d.Kind.String(),
+					Message: d.Message,
+					Range:   d.Range.Start.String() + "-" + d.Range.End.String(),
+				})
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHintCatalogCustomSpec(t *testing.T) {
+	catalog := gsqlutils.SpannerHintCatalog()
+	catalog["MY_HINT"] = gsqlutils.HintSpec{Key: "MY_HINT", Scopes: []gsqlutils.HintScope{gsqlutils.HintScopeStatement}, Kind: gsqlutils.HintValueBool}
+
+	diagnostics, err := gsqlutils.ValidateHints("", "@{my_hint=TRUE} SELECT 1", catalog)
+	if err != nil {
+		t.Fatalf("should success, but failed: %v", err)
+	}
+	if len(diagnostics) > 0 {
+		t.Errorf("custom spec should be valid, but got %v", diagnostics)
+	}
+
+	if _, ok := gsqlutils.SpannerHintCatalog()["MY_HINT"]; ok {
+		t.Errorf("SpannerHintCatalog should return a copy")
+	}
+}
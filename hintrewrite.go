@@ -0,0 +1,286 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils/keywords"
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+// HintEdit is an edit of hint records for RewriteHints.
+type HintEdit struct {
+	// Table is the name of the table whose table hints are edited, e.g. `Singers` or `sch.Singers`.
+	// It is compared case-insensitively. If it is empty, statement hints are edited.
+	Table string
+
+	// Key is the key of the record.
+	Key string
+
+	// Value is the source text of the new value, e.g. `7`, `_BASE_TABLE`, `'latest'`.
+	// It is ignored if Remove is true.
+	Value string
+
+	// Remove removes the record of Key instead of setting it. A hint without records is removed entirely.
+	Remove bool
+}
+
+// SetStatementHint sets key=value to statement hints.
+func SetStatementHint(key, value string) HintEdit {
+	return HintEdit{Key: key, Value: value}
+}
+
+// SetTableHint sets key=value to table hints of table.
+func SetTableHint(table, key, value string) HintEdit {
+	return HintEdit{Table: table, Key: key, Value: value}
+}
+
+// RemoveStatementHint removes key from statement hints.
+func RemoveStatementHint(key string) HintEdit {
+	return HintEdit{Key: key, Remove: true}
+}
+
+// RemoveTableHint removes key from table hints of table.
+func RemoveTableHint(table, key string) HintEdit {
+	return HintEdit{Table: table, Key: key, Remove: true}
+}
+
+// RewriteHints applies edits to hints of all statements in s in order.
+// A record is merged into the existing hint, and a new hint is created only if there is no hint at the position.
+// Existing keys are compared case-insensitively and without `spanner.` qualifier, and their values are replaced in place.
+// Statement hints are added only to query, graph query and DML statements, and table hints are added only to table names in FROM clauses.
+// Text other than edited records, including comments and whitespaces, is preserved byte-for-byte.
+// filepath can be empty, it is only used in error message.
+func RewriteHints(filepath, s string, edits ...HintEdit) (string, error) {
+	for _, edit := range edits {
+		var err error
+		s, err = rewriteHints(filepath, s, edit)
+		if err != nil {
+			return "", fmt.Errorf("error on RewriteHints, err: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// hintAnchor is a position where a hint can be attached.
+type hintAnchor struct {
+	// hint is the existing hint, or nil.
+	hint *Hint
+
+	// pos is the position to insert a new hint.
+	pos token.Pos
+
+	// format is the format of a new hint.
+	format string
+}
+
+// textEdit replaces s[pos:end] with text.
+type textEdit struct {
+	pos, end token.Pos
+	text     string
+}
+
+func rewriteHints(filepath, s string, edit HintEdit) (string, error) {
+	var tokens []tokenfilter.HintToken
+	for tok, err := range newAnnotateHintsSeq(filepath, s) {
+		if err != nil {
+			return "", err
+		}
+		tokens = append(tokens, tok)
+	}
+
+	hints, err := extractHints(s, tokens)
+	if err != nil {
+		return "", err
+	}
+
+	var anchors []hintAnchor
+	if edit.Table == "" {
+		anchors = statementHintAnchors(tokens, hints)
+	} else {
+		anchors = tableHintAnchors(tokens, hints, edit.Table)
+	}
+
+	var textEdits []textEdit
+	for _, anchor := range anchors {
+		if e, ok := hintTextEdit(s, anchor, edit); ok {
+			textEdits = append(textEdits, e)
+		}
+	}
+
+	// Apply from the tail, so positions of preceding edits are not shifted.
+	for _, e := range slices.Backward(textEdits) {
+		s = s[:e.pos] + e.text + s[e.end:]
+	}
+	return s, nil
+}
+
+// hintAt returns the hint which starts at pos.
+func hintAt(hints []Hint, pos token.Pos) *Hint {
+	for i := range hints {
+		if hints[i].Range.Start.Pos == pos {
+			return &hints[i]
+		}
+	}
+	return nil
+}
+
+// statementHintFirstTokens are first tokens of query, graph query and DML statements, which can have statement hints.
+var statementHintFirstTokens = slices.Concat(keywords.QueryFirstTokens(), keywords.GraphFirstTokens(), keywords.DMLFirstTokens())
+
+// statementHintAnchors returns anchors of statement hints of query, graph query and DML statements.
+func statementHintAnchors(tokens []tokenfilter.HintToken, hints []Hint) []hintAnchor {
+	var result []hintAnchor
+	head := true
+	for _, tok := range tokens {
+		switch {
+		case tok.Kind == ";":
+			head = true
+		case !head || tok.Role == tokenfilter.HintRoleBody || tok.Role == tokenfilter.HintRoleOpen || tok.Role == tokenfilter.HintRoleClose:
+			continue
+		case tok.Role == tokenfilter.HintRoleAt:
+			result = append(result, hintAnchor{hint: hintAt(hints, tok.Pos)})
+			head = false
+		case tok.Kind == token.TokenEOF:
+			return result
+		default:
+			if keywords.MatchAny(tok.Token, statementHintFirstTokens...) {
+				result = append(result, hintAnchor{pos: tok.Pos, format: "@{%v} "})
+			}
+			head = false
+		}
+	}
+	return result
+}
+
+// fromClauseEnds are keywords which end FROM clause.
+var fromClauseEnds = []string{
+	"SELECT", "WHERE", "GROUP", "HAVING", "QUALIFY", "WINDOW", "ORDER", "LIMIT", "OFFSET",
+	"UNION", "INTERSECT", "EXCEPT", "SET", "THEN",
+}
+
+// tableHintAnchors returns anchors of table hints of table names in FROM clauses which match table.
+func tableHintAnchors(tokens []tokenfilter.HintToken, hints []Hint, table string) []hintAnchor {
+	var result []hintAnchor
+
+	// levels is a stack of the state of each parenthesis level.
+	levels := []fromLevel{{query: true}}
+	var prev token.Token
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Role != tokenfilter.HintRoleNone {
+			continue
+		}
+
+		top := &levels[len(levels)-1]
+		switch {
+		case tok.Kind == "(":
+			levels = append(levels, fromLevel{query: isSubqueryParen(tokens[i:])})
+		case tok.Kind == ")" && len(levels) > 1:
+			levels = levels[:len(levels)-1]
+		case tok.Kind == ";":
+			levels = []fromLevel{{query: true}}
+		case keywords.Match(tok.Token, "FROM"):
+			// FROM in function calls, e.g. `EXTRACT(YEAR FROM ts)`, is not a FROM clause.
+			top.inFrom = top.query
+		case tok.Kind == token.TokenIdent && top.inFrom && keywords.MatchAny(prev, "FROM", "JOIN", ","):
+			// Consume a path expression.
+			names := []string{tok.AsString}
+			for i+2 < len(tokens) && tokens[i+1].Kind == "." && tokens[i+2].Kind == token.TokenIdent {
+				names = append(names, tokens[i+2].AsString)
+				i += 2
+			}
+			tok = tokens[i]
+
+			if !strings.EqualFold(strings.Join(names, "."), table) {
+				break
+			}
+			if i+1 < len(tokens) && tokens[i+1].Role == tokenfilter.HintRoleAt {
+				result = append(result, hintAnchor{hint: hintAt(hints, tokens[i+1].Pos)})
+			} else {
+				result = append(result, hintAnchor{pos: tok.End, format: "@{%v}"})
+			}
+		case keywords.MatchAny(tok.Token, fromClauseEnds...):
+			top.inFrom = false
+		}
+		prev = tok.Token
+	}
+	return result
+}
+
+// fromLevel is the state of a parenthesis level in tableHintAnchors.
+type fromLevel struct {
+	// query is true if the level is the top level of a statement or a subquery.
+	query bool
+
+	// inFrom is true in FROM clause of the level.
+	inFrom bool
+}
+
+// isSubqueryParen returns true if tokens start with a parenthesis of a subquery, e.g. `(SELECT`, `((WITH`.
+func isSubqueryParen(tokens []tokenfilter.HintToken) bool {
+	for _, tok := range tokens {
+		switch {
+		case tok.Role != tokenfilter.HintRoleNone, tok.Kind == "(":
+			continue
+		default:
+			return keywords.MatchAny(tok.Token, "SELECT", "WITH", "FROM")
+		}
+	}
+	return false
+}
+
+// hintTextEdit returns the text edit to apply edit at anchor. It returns false if nothing is changed.
+func hintTextEdit(s string, anchor hintAnchor, edit HintEdit) (textEdit, bool) {
+	record := edit.Key + "=" + edit.Value
+	h := anchor.hint
+	if h == nil {
+		if edit.Remove {
+			return textEdit{}, false
+		}
+		return textEdit{pos: anchor.pos, end: anchor.pos, text: fmt.Sprintf(anchor.format, record)}, true
+	}
+
+	k := slices.IndexFunc(h.Records, func(r HintRecord) bool {
+		return strings.EqualFold(trimSpannerQualifier(r.Key), trimSpannerQualifier(edit.Key))
+	})
+
+	switch {
+	case !edit.Remove && k >= 0:
+		r := h.Records[k]
+		return textEdit{pos: r.ValueRange.Start.Pos, end: r.ValueRange.End.Pos, text: edit.Value}, true
+	case !edit.Remove && len(h.Records) > 0:
+		end := h.Records[len(h.Records)-1].ValueRange.End.Pos
+		return textEdit{pos: end, end: end, text: ", " + record}, true
+	case !edit.Remove:
+		// Insert into `@{}` before "}".
+		end := h.Range.End.Pos - 1
+		return textEdit{pos: end, end: end, text: record}, true
+	case k < 0:
+		return textEdit{}, false
+	case len(h.Records) == 1:
+		pos, end := h.Range.Start.Pos, h.Range.End.Pos
+
+		// Also remove spaces after the hint if it is preceded by a whitespace, e.g. `@{...} SELECT`, `Singers @{...} AS s`.
+		if pos == 0 || strings.ContainsRune(" \t\n", rune(s[pos-1])) {
+			end += token.Pos(len(s[end:]) - len(strings.TrimLeft(s[end:], " \t")))
+		}
+		return textEdit{pos: pos, end: end}, true
+	case k == 0:
+		return textEdit{pos: h.Records[0].KeyRange.Start.Pos, end: h.Records[1].KeyRange.Start.Pos}, true
+	default:
+		return textEdit{pos: h.Records[k-1].ValueRange.End.Pos, end: h.Records[k].ValueRange.End.Pos}, true
+	}
+}
+
+// trimSpannerQualifier trims `spanner.` qualifier of a hint key case-insensitively, e.g. `spanner.optimizer_version`.
+func trimSpannerQualifier(key string) string {
+	const qualifier = "spanner."
+	if len(key) >= len(qualifier) && strings.EqualFold(key[:len(qualifier)], qualifier) {
+		return key[len(qualifier):]
+	}
+	return key
+}
@@ -0,0 +1,119 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestRewriteHints(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		edits []gsqlutils.HintEdit
+		want  string
+	}{
+		{
+			desc:  "add statement hint",
+			input: "-- comment\nSELECT * FROM Singers",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetStatementHint("OPTIMIZER_VERSION", "7")},
+			want:  "-- comment\n@{OPTIMIZER_VERSION=7} SELECT * FROM Singers",
+		},
+		{
+			desc:  "merge into existing statement hint",
+			input: "@{ USE_ADDITIONAL_PARALLELISM = TRUE /* keep */ } SELECT 1",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetStatementHint("OPTIMIZER_VERSION", "7")},
+			want:  "@{ USE_ADDITIONAL_PARALLELISM = TRUE, OPTIMIZER_VERSION=7 /* keep */ } SELECT 1",
+		},
+		{
+			desc:  "override existing key case-insensitively",
+			input: "@{optimizer_version = 5} SELECT 1",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetStatementHint("OPTIMIZER_VERSION", "7")},
+			want:  "@{optimizer_version = 7} SELECT 1",
+		},
+		{
+			desc:  "override existing key with spanner qualifier",
+			input: "@{spanner.optimizer_version=6} SELECT 1; @{OPTIMIZER_VERSION=6} SELECT 2",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetStatementHint("OPTIMIZER_VERSION", "7"), gsqlutils.SetStatementHint("Spanner.Optimizer_Version", "8")},
+			want:  "@{spanner.optimizer_version=8} SELECT 1; @{OPTIMIZER_VERSION=8} SELECT 2",
+		},
+		{
+			desc:  "empty hint",
+			input: "@{} SELECT 1",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetStatementHint("OPTIMIZER_VERSION", "7")},
+			want:  "@{OPTIMIZER_VERSION=7} SELECT 1",
+		},
+		{
+			desc:  "multiple statements without DDL",
+			input: "SELECT 1;\nCREATE TABLE t (x INT64) PRIMARY KEY (x);\nUPDATE t SET x = 1 WHERE TRUE;\n",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetStatementHint("OPTIMIZER_VERSION", "7")},
+			want:  "@{OPTIMIZER_VERSION=7} SELECT 1;\nCREATE TABLE t (x INT64) PRIMARY KEY (x);\n@{OPTIMIZER_VERSION=7} UPDATE t SET x = 1 WHERE TRUE;\n",
+		},
+		{
+			desc:  "statement hints only for queries and DML",
+			input: "SELECT 1; BEGIN; INSERT INTO t (x) VALUES (1); COMMIT; (SELECT 2); GRANT SELECT ON TABLE t TO ROLE r",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetStatementHint("OPTIMIZER_VERSION", "7")},
+			want:  "@{OPTIMIZER_VERSION=7} SELECT 1; BEGIN; @{OPTIMIZER_VERSION=7} INSERT INTO t (x) VALUES (1); COMMIT; @{OPTIMIZER_VERSION=7} (SELECT 2); GRANT SELECT ON TABLE t TO ROLE r",
+		},
+		{
+			desc:  "statement hint for graph query",
+			input: "GRAPH FinGraph MATCH (n) RETURN n",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetStatementHint("OPTIMIZER_VERSION", "7")},
+			want:  "@{OPTIMIZER_VERSION=7} GRAPH FinGraph MATCH (n) RETURN n",
+		},
+		{
+			desc:  "add table hint",
+			input: "SELECT s.Name FROM Singers AS s JOIN singers t ON s.Id = t.Id, (SELECT * FROM Singers) WHERE Singers.x IN (SELECT 1 FROM Albums)",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetTableHint("Singers", "FORCE_INDEX", "_BASE_TABLE")},
+			want:  "SELECT s.Name FROM Singers@{FORCE_INDEX=_BASE_TABLE} AS s JOIN singers@{FORCE_INDEX=_BASE_TABLE} t ON s.Id = t.Id, (SELECT * FROM Singers@{FORCE_INDEX=_BASE_TABLE}) WHERE Singers.x IN (SELECT 1 FROM Albums)",
+		},
+		{
+			desc:  "FROM in function calls is not FROM clause",
+			input: "SELECT EXTRACT(YEAR FROM Singers), (SELECT x FROM ((SELECT * FROM Singers))) FROM Singers",
+			edits: []gsqlutils.HintEdit{gsqlutils.SetTableHint("Singers", "FORCE_INDEX", "_BASE_TABLE")},
+			want:  "SELECT EXTRACT(YEAR FROM Singers), (SELECT x FROM ((SELECT * FROM Singers@{FORCE_INDEX=_BASE_TABLE}))) FROM Singers@{FORCE_INDEX=_BASE_TABLE}",
+		},
+		{
+			desc:  "merge into existing table hint",
+			input: "SELECT * FROM sch.Singers @{FORCE_INDEX=SingersByName}, Albums",
+			edits: []gsqlutils.HintEdit{
+				gsqlutils.SetTableHint("sch.Singers", "FORCE_INDEX", "_BASE_TABLE"),
+				gsqlutils.SetTableHint("SCH.SINGERS", "SCAN_METHOD", "BATCH"),
+			},
+			want: "SELECT * FROM sch.Singers @{FORCE_INDEX=_BASE_TABLE, SCAN_METHOD=BATCH}, Albums",
+		},
+		{
+			desc:  "remove keys",
+			input: "@{A=1, B=2, C=3} SELECT * FROM Singers @{FORCE_INDEX=idx} s",
+			edits: []gsqlutils.HintEdit{
+				gsqlutils.RemoveStatementHint("b"),
+				gsqlutils.RemoveStatementHint("A"),
+				gsqlutils.RemoveTableHint("Singers", "FORCE_INDEX"),
+				gsqlutils.RemoveTableHint("Albums", "FORCE_INDEX"),
+			},
+			want: "@{C=3} SELECT * FROM Singers s",
+		},
+		{
+			desc:  "remove the last key of statement hint",
+			input: "@{OPTIMIZER_VERSION=7}  SELECT 1",
+			edits: []gsqlutils.HintEdit{gsqlutils.RemoveStatementHint("OPTIMIZER_VERSION")},
+			want:  "SELECT 1",
+		},
+		{
+			desc:  "remove missing key",
+			input: "SELECT 1",
+			edits: []gsqlutils.HintEdit{gsqlutils.RemoveStatementHint("OPTIMIZER_VERSION")},
+			want:  "SELECT 1",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.RewriteHints("", tt.input, tt.edits...)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("want %q, but got %q", tt.want, got)
+			}
+		})
+	}
+}
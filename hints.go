@@ -0,0 +1,225 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+// HintScope is a position where a hint is attached.
+type HintScope int
+
+const (
+	HintScopeInvalid HintScope = iota
+
+	// HintScopeStatement is a statement hint, e.g. `@{OPTIMIZER_VERSION=7} SELECT ...`.
+	HintScopeStatement
+
+	// HintScopeTable is a table hint, e.g. `FROM Singers@{FORCE_INDEX=_BASE_TABLE}`.
+	HintScopeTable
+
+	// HintScopeIndex is a table hint which has FORCE_INDEX, e.g. `FROM Singers@{FORCE_INDEX=SingersByName}`.
+	HintScopeIndex
+
+	// HintScopeJoin is a join hint, e.g. `JOIN@{JOIN_METHOD=HASH_JOIN}`.
+	HintScopeJoin
+
+	// HintScopeSubquery is a hint of an IN subquery, e.g. `IN@{JOIN_METHOD=APPLY_JOIN} (SELECT ...)`.
+	HintScopeSubquery
+)
+
+func (s HintScope) String() string {
+	switch s {
+	case HintScopeStatement:
+		return "Statement"
+	case HintScopeTable:
+		return "Table"
+	case HintScopeIndex:
+		return "Index"
+	case HintScopeJoin:
+		return "Join"
+	case HintScopeSubquery:
+		return "Subquery"
+	case HintScopeInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(s))
+	}
+}
+
+// HintRecord is a key-value pair in a hint.
+type HintRecord struct {
+	// Key is the key in the original case, e.g. `FORCE_INDEX`, `spanner.group_by_scan_optimization`.
+	Key string
+
+	// Value is the source text of the value in the original case, e.g. `_BASE_TABLE`, `TRUE`, `'x'`.
+	Value string
+
+	KeyRange, ValueRange Range
+}
+
+// Hint is a hint `@{...}` in a statement.
+type Hint struct {
+	Scope HintScope
+
+	// Records are the key-value pairs in order.
+	Records []HintRecord
+
+	// Target is the token the hint is attached to.
+	// It is the first token of the statement for statement hints, the JOIN keyword for join hints,
+	// the IN keyword for subquery hints, and the last token of the table expression for table hints.
+	Target token.Token
+
+	// Range is the range from `@` to `}`.
+	Range Range
+}
+
+// Lookup returns the record of key. Keys are compared case-insensitively.
+func (h Hint) Lookup(key string) (HintRecord, bool) {
+	for _, r := range h.Records {
+		if strings.EqualFold(r.Key, key) {
+			return r, true
+		}
+	}
+	return HintRecord{}, false
+}
+
+// ExtractHints returns all hints in s in order of positions.
+// s can contain multiple statements.
+// filepath can be empty, it is only used in error message.
+func ExtractHints(filepath, s string) ([]Hint, error) {
+	var tokens []tokenfilter.HintToken
+	for tok, err := range newAnnotateHintsSeq(filepath, s) {
+		if err != nil {
+			return nil, fmt.Errorf("error on ExtractHints, err: %w", err)
+		}
+		tokens = append(tokens, tok)
+	}
+
+	hints, err := extractHints(s, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("error on ExtractHints, err: %w", err)
+	}
+	return hints, nil
+}
+
+// extractHints returns all hints in tokens of s annotated by AnnotateHints.
+func extractHints(s string, tokens []tokenfilter.HintToken) ([]Hint, error) {
+	idx := NewPositionIndex(s)
+	var result []Hint
+	for i, tok := range tokens {
+		if tok.Role != tokenfilter.HintRoleAt {
+			continue
+		}
+
+		end := i + 1
+		for tokens[end].Role != tokenfilter.HintRoleClose {
+			end++
+		}
+
+		records, err := hintRecords(s, idx, tokens[i+2:end])
+		if err != nil {
+			return nil, err
+		}
+
+		hint := Hint{Records: records, Range: idx.Range(tok.Pos, tokens[end].End)}
+		hint.Scope, hint.Target = hintScope(tokens[:i], tokens[end+1:])
+		if _, ok := hint.Lookup("FORCE_INDEX"); ok && hint.Scope == HintScopeTable {
+			hint.Scope = HintScopeIndex
+		}
+		result = append(result, hint)
+	}
+	return result, nil
+}
+
+// hintScope determines the scope and the target of a hint by the tokens before and after the hint.
+func hintScope(before, after []tokenfilter.HintToken) (HintScope, token.Token) {
+	prev, hasPrev := lastNonHintToken(before)
+	next, _ := firstNonHintToken(after)
+	switch {
+	case !hasPrev || prev.Kind == ";":
+		return HintScopeStatement, next
+	case prev.Kind == "JOIN":
+		return HintScopeJoin, prev
+	case prev.Kind == "IN" && next.Kind == "(":
+		return HintScopeSubquery, prev
+	case prev.Kind == token.TokenIdent || prev.Kind == ")" || prev.Kind == "]":
+		return HintScopeTable, prev
+	default:
+		return HintScopeInvalid, prev
+	}
+}
+
+func lastNonHintToken(tokens []tokenfilter.HintToken) (token.Token, bool) {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].Role == tokenfilter.HintRoleNone {
+			return tokens[i].Token, true
+		}
+	}
+	return token.Token{}, false
+}
+
+func firstNonHintToken(tokens []tokenfilter.HintToken) (token.Token, bool) {
+	for _, tok := range tokens {
+		if tok.Role == tokenfilter.HintRoleNone {
+			return tok.Token, true
+		}
+	}
+	return token.Token{}, false
+}
+
+// hintRecords parses body of a hint, which is `key = value, ...`.
+// Keys can be qualified, and values are kept as source text.
+func hintRecords(s string, idx *PositionIndex, body []tokenfilter.HintToken) ([]HintRecord, error) {
+	var result []HintRecord
+	for len(body) > 0 {
+		eq := -1
+		sep := len(body)
+		level := 0
+	loop:
+		for j, tok := range body {
+			switch tok.Kind {
+			case "(", "[", "{":
+				level++
+			case ")", "]", "}":
+				level--
+			case "=":
+				if eq < 0 && level == 0 {
+					eq = j
+				}
+			case ",":
+				if level == 0 {
+					sep = j
+					break loop
+				}
+			}
+		}
+
+		record := body[:sep]
+		if eq <= 0 || eq == len(record)-1 {
+			return nil, fmt.Errorf("%v: invalid hint record, want key=value", idx.Location(body[0].Pos))
+		}
+
+		key, value := record[:eq], record[eq+1:]
+		keyPos, keyEnd := key[0].Pos, key[len(key)-1].End
+		valuePos, valueEnd := value[0].Pos, value[len(value)-1].End
+		result = append(result, HintRecord{
+			Key:        s[keyPos:keyEnd],
+			Value:      s[valuePos:valueEnd],
+			KeyRange:   idx.Range(keyPos, keyEnd),
+			ValueRange: idx.Range(valuePos, valueEnd),
+		})
+
+		if sep == len(body) {
+			break
+		}
+		body = body[sep+1:]
+		if len(body) == 0 {
+			return nil, fmt.Errorf("%v: trailing comma in hint", idx.Location(record[len(record)-1].End))
+		}
+	}
+	return result, nil
+}
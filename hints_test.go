@@ -0,0 +1,122 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestExtractHints(t *testing.T) {
+	type hint struct {
+		Scope   string
+		Records []string
+		Target  string
+		Range   string
+	}
+
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []hint
+	}{
+		{desc: "no hints", input: "SELECT @p FROM t", want: nil},
+		{
+			desc:  "statement hint",
+			input: "@{OPTIMIZER_VERSION=7, optimizer_statistics_package=latest} SELECT 1",
+			want: []hint{
+				{Scope: "Statement", Records: []string{"OPTIMIZER_VERSION=7", "optimizer_statistics_package=latest"}, Target: "SELECT", Range: "1:1-1:60"},
+			},
+		},
+		{
+			desc:  "table, index and join hints",
+			input: "SELECT * FROM Singers@{FORCE_INDEX = SingersByName} s\nJOIN @{JOIN_METHOD=HASH_JOIN} Albums@{scan_method=BATCH} a USING (SingerId)",
+			want: []hint{
+				{Scope: "Index", Records: []string{"FORCE_INDEX=SingersByName"}, Target: "Singers", Range: "1:22-1:52"},
+				{Scope: "Join", Records: []string{"JOIN_METHOD=HASH_JOIN"}, Target: "JOIN", Range: "2:6-2:30"},
+				{Scope: "Table", Records: []string{"scan_method=BATCH"}, Target: "Albums", Range: "2:37-2:57"},
+			},
+		},
+		{
+			desc:  "subquery hint",
+			input: "SELECT * FROM t WHERE x IN @{JOIN_METHOD=APPLY_JOIN} (SELECT y FROM u)",
+			want: []hint{
+				{Scope: "Subquery", Records: []string{"JOIN_METHOD=APPLY_JOIN"}, Target: "IN", Range: "1:28-1:53"},
+			},
+		},
+		{
+			desc:  "nested braces",
+			input: "@{a={b=1, c=(2)}, d=3} SELECT 1",
+			want: []hint{
+				{Scope: "Statement", Records: []string{"a={b=1, c=(2)}", "d=3"}, Target: "SELECT", Range: "1:1-1:23"},
+			},
+		},
+		{
+			desc:  "qualified key and comments",
+			input: "@{spanner.group_by_scan_optimization=TRUE /* c */} SELECT 1; @{USE_ADDITIONAL_PARALLELISM=true}\nSELECT 2",
+			want: []hint{
+				{Scope: "Statement", Records: []string{"spanner.group_by_scan_optimization=TRUE"}, Target: "SELECT", Range: "1:1-1:51"},
+				{Scope: "Statement", Records: []string{"USE_ADDITIONAL_PARALLELISM=true"}, Target: "SELECT", Range: "1:62-1:96"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			hints, err := gsqlutils.ExtractHints("", tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+
+			var got []hint
+			for _, h := range hints {
+				var records []string
+				for _, r := range h.Records {
+					records = append(records, r.Key+"="+r.Value)
+				}
+				got = append(got, hint{
+					Scope:   h.Scope.String(),
+					Records: records,
+					Target:  h.Target.Raw,
+					Range:   h.Range.Start.String() + "-" + h.Range.End.String(),
+				})
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExtractHintsRecordRange(t *testing.T) {
+	input := "SELECT 1 FROM t@{\n  force_index = idx }"
+	hints, err := gsqlutils.ExtractHints("", input)
+	if err != nil {
+		t.Fatalf("should success, but failed: %v", err)
+	}
+
+	r, ok := hints[0].Lookup("FORCE_INDEX")
+	if !ok {
+		t.Fatalf("FORCE_INDEX should be found case-insensitively")
+	}
+	if got := input[r.KeyRange.Start.Pos:r.KeyRange.End.Pos]; got != "force_index" {
+		t.Errorf("want key force_index, but got %v", got)
+	}
+	if got := r.ValueRange.Start.String(); got != "2:17" {
+		t.Errorf("want value at 2:17, but got %v", got)
+	}
+}
+
+func TestExtractHintsError(t *testing.T) {
+	for _, input := range []string{
+		"@{OPTIMIZER_VERSION=7 SELECT 1",
+		"@{OPTIMIZER_VERSION} SELECT 1",
+		"@{OPTIMIZER_VERSION=} SELECT 1",
+		"@{OPTIMIZER_VERSION=7,} SELECT 1",
+		"@{,OPTIMIZER_VERSION=7} SELECT 1",
+	} {
+		if got, err := gsqlutils.ExtractHints("", input); err == nil {
+			t.Errorf("%q should fail, but success: %v", input, got)
+		}
+	}
+}
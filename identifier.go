@@ -0,0 +1,104 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils/keywords"
+)
+
+// NeedsQuoting is true if s can't be an unquoted identifier.
+// It means s is empty, a reserved keyword, starts with a digit, or contains characters other than ASCII letters, digits and underscores.
+func NeedsQuoting(s string) bool {
+	return s == "" || keywords.IsReserved(s) || !char.IsIdentStart(s[0]) || !isIdentParts(s)
+}
+
+// isIdentParts is true if all characters of s can be a part of an unquoted identifier.
+func isIdentParts(s string) bool {
+	for i := range len(s) {
+		if !char.IsIdentPart(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// QuoteIdentifier returns s as is if it can be an unquoted identifier, otherwise it returns s quoted with backticks.
+// Backticks, backslashes and non-printable characters are escaped.
+// Note: An empty identifier is quoted as two backticks, but it is invalid in GoogleSQL.
+func QuoteIdentifier(s string) string {
+	if !NeedsQuoting(s) {
+		return s
+	}
+	return quoteIdentifier(s)
+}
+
+func quoteIdentifier(s string) string {
+	var b strings.Builder
+	b.WriteByte('`')
+	for _, r := range s {
+		switch {
+		case r == '`' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case unicode.IsPrint(r):
+			b.WriteRune(r)
+		case r < 0x80:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case r > 0xFFFF:
+			fmt.Fprintf(&b, `\U%08x`, r)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	b.WriteByte('`')
+	return b.String()
+}
+
+// QuotePath quotes names of a path expression and joins them with dots, e.g. a.`b c`.select.
+// The first name is quoted by QuoteIdentifier.
+// Names after the first can be reserved keywords or start with digits without quoting, because they always follow a dot.
+func QuotePath(names ...string) string {
+	quoted := make([]string, 0, len(names))
+	for i, name := range names {
+		if i > 0 && name != "" && isIdentParts(name) {
+			quoted = append(quoted, name)
+			continue
+		}
+		quoted = append(quoted, QuoteIdentifier(name))
+	}
+	return strings.Join(quoted, ".")
+}
+
+// UnquoteIdentifier returns the name of an identifier, which is an unquoted identifier or an identifier quoted with backticks.
+// It returns an error if s is not a single identifier, e.g. a reserved keyword or an identifier with surrounding whitespaces.
+func UnquoteIdentifier(s string) (string, error) {
+	var result token.Token
+	for tok, err := range NewLexerSeq("", s) {
+		if err != nil {
+			return "", fmt.Errorf("error on UnquoteIdentifier, err: %w", err)
+		}
+
+		switch {
+		case tok.Kind == token.TokenEOF && result.Kind == token.TokenIdent:
+			return result.AsString, nil
+		case result.Kind == "" && tok.Kind == token.TokenIdent && tok.Pos == 0 && tok.End == token.Pos(len(s)):
+			result = tok
+		case keywords.IsKeywordToken(tok):
+			return "", fmt.Errorf("reserved keyword %v can't be an unquoted identifier", tok.Raw)
+		default:
+			return "", fmt.Errorf("not a single identifier: %q", s)
+		}
+	}
+	return "", fmt.Errorf("not a single identifier: %q", s)
+}
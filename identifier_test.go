@@ -0,0 +1,87 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  string
+	}{
+		{input: "Singers", want: "Singers"},
+		{input: "_a1", want: "_a1"},
+		{input: "select", want: "`select`"},
+		{input: "Insert", want: "Insert"},
+		{input: "1st", want: "`1st`"},
+		{input: "first name", want: "`first name`"},
+		{input: "日本語", want: "`日本語`"},
+		{input: "a`b\\c\n", want: "`a\\`b\\\\c\\n`"},
+		{input: "\x00", want: "`\\x00`"},
+		{input: "", want: "``"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			got := gsqlutils.QuoteIdentifier(tt.input)
+			if got != tt.want {
+				t.Errorf("want %v, but got %v", tt.want, got)
+			}
+			if gsqlutils.NeedsQuoting(tt.input) != (got != tt.input) {
+				t.Errorf("NeedsQuoting(%q) is inconsistent with QuoteIdentifier", tt.input)
+			}
+
+			if tt.input == "" {
+				return
+			}
+			unquoted, err := gsqlutils.UnquoteIdentifier(got)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if unquoted != tt.input {
+				t.Errorf("round trip failed: want %q, but got %q", tt.input, unquoted)
+			}
+		})
+	}
+}
+
+func TestQuotePath(t *testing.T) {
+	for _, tt := range []struct {
+		input []string
+		want  string
+	}{
+		{input: []string{"a", "b", "c"}, want: "a.b.c"},
+		{input: []string{"select", "from", "1"}, want: "`select`.from.1"},
+		{input: []string{"my-project", "a b"}, want: "`my-project`.`a b`"},
+	} {
+		if got := gsqlutils.QuotePath(tt.input...); got != tt.want {
+			t.Errorf("want %v, but got %v", tt.want, got)
+		}
+	}
+}
+
+func TestUnquoteIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  string
+	}{
+		{input: "a", want: "a"},
+		{input: "`a b`", want: "a b"},
+		{input: "`select`", want: "select"},
+		{input: "`\\x41\\u00e9`", want: "Aé"},
+	} {
+		got, err := gsqlutils.UnquoteIdentifier(tt.input)
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("want %q, but got %q", tt.want, got)
+		}
+	}
+
+	for _, input := range []string{"", "select", " a", "a.b", "`a", "``", "'a'", "1a"} {
+		if got, err := gsqlutils.UnquoteIdentifier(input); err == nil {
+			t.Errorf("%q should fail, but success: %q", input, got)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils/literal"
+)
+
+// InlineParameters replaces query parameters in s with GoogleSQL literals of values in params, encoded by literal.Encode.
+// It is the inverse of Parameterize, for EXPLAIN, debugging and log replay.
+// params are keyed by names without `@`, and names are case-insensitive.
+// ParameterValue in params is encoded by ParameterValue.Literal, so values returned by Parameterize keep their types.
+// Hints, system variables, comments and literals containing `@` are not changed.
+// It returns an error if a referenced parameter is not in params, or its value can't be encoded.
+// filepath can be empty, it is only used in error message.
+func InlineParameters(filepath, s string, params map[string]any) (string, error) {
+	values := make(map[string]any, len(params))
+	for name, v := range params {
+		values[strings.ToUpper(name)] = v
+	}
+
+	var replaced []token.Token
+	for tok, err := range newStripHintsSeq(filepath, s) {
+		if err != nil {
+			return "", fmt.Errorf("error on InlineParameters, err: %w", err)
+		}
+
+		if tok.Kind != token.TokenParam {
+			continue
+		}
+
+		v, ok := values[strings.ToUpper(tok.AsString)]
+		if !ok {
+			return "", fmt.Errorf("no value for parameter %v at %v", tok.Raw, tok.Pos)
+		}
+
+		lit, err := encodeParameter(v)
+		if err != nil {
+			return "", fmt.Errorf("can't encode parameter %v, err: %w", tok.Raw, err)
+		}
+
+		tok.Raw = separateReplacement(s, tok.Pos, tok.End, lit)
+		replaced = append(replaced, tok)
+	}
+
+	result, err := RebuildSource(s, tokenSliceSeq(replaced))
+	if err != nil {
+		return "", fmt.Errorf("error on InlineParameters, err: %w", err)
+	}
+	return result, nil
+}
+
+// encodeParameter encodes v by ParameterValue.Literal if v is ParameterValue, otherwise by literal.Encode.
+func encodeParameter(v any) (string, error) {
+	if pv, ok := v.(ParameterValue); ok {
+		return pv.Literal()
+	}
+	return literal.Encode(v)
+}
+
+// separateReplacement adds whitespaces around replacement of s[pos:end] if it would be joined with the adjacent tokens,
+// e.g. `SELECT@p` to `SELECT 1` and `a-@p` to `a- -1`.
+func separateReplacement(s string, pos, end token.Pos, replacement string) string {
+	if replacement == "" {
+		return replacement
+	}
+
+	if pos > 0 {
+		prev, head := s[pos-1], replacement[0]
+		if (char.IsIdentPart(prev) && char.IsIdentPart(head)) || (prev == '-' && head == '-') {
+			replacement = " " + replacement
+		}
+	}
+
+	if int(end) < len(s) && char.IsIdentPart(s[end]) && char.IsIdentPart(replacement[len(replacement)-1]) {
+		replacement += " "
+	}
+	return replacement
+}
@@ -0,0 +1,80 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestInlineParameters(t *testing.T) {
+	params := map[string]any{
+		"id":   int64(-1),
+		"Name": "O'Reilly @x",
+		"tags": []string{"a", "b"},
+		"none": (*int64)(nil),
+	}
+
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  string
+	}{
+		{
+			desc:  "values",
+			input: "SELECT * FROM t WHERE id = @id AND name = @name AND tag IN UNNEST(@tags) AND x = @none",
+			want:  `SELECT * FROM t WHERE id = -1 AND name = "O'Reilly @x" AND tag IN UNNEST(ARRAY<STRING>["a", "b"]) AND x = CAST(NULL AS INT64)`,
+		},
+		{
+			desc:  "hints, system variables, comments and strings",
+			input: "@{OPTIMIZER_VERSION=7} SELECT @@x, '@id', /* @id */ @ID FROM t @{FORCE_INDEX=idx}",
+			want:  "@{OPTIMIZER_VERSION=7} SELECT @@x, '@id', /* @id */ -1 FROM t @{FORCE_INDEX=idx}",
+		},
+		{
+			desc:  "separation",
+			input: "SELECT@tags, 1-@id, @id-1",
+			want:  `SELECT ARRAY<STRING>["a", "b"], 1- -1, -1-1`,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.InlineParameters("", tt.input, params)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("round trip with Parameterize", func(t *testing.T) {
+		const input = "SELECT * FROM t WHERE a = -1 AND b = 'x' AND c = b'y' AND d = 1.5 AND e = DATE '2020-01-02' " +
+			"AND f = TIMESTAMP '2020-01-02T03:04:05Z' AND g = NUMERIC '1.25' AND h = JSON '{\"a\": 1}'"
+		parameterized, values, err := gsqlutils.Parameterize("", input)
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+
+		anyValues := make(map[string]any)
+		for name, v := range values {
+			anyValues[name] = v
+		}
+
+		got, err := gsqlutils.InlineParameters("", parameterized, anyValues)
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+		want := `SELECT * FROM t WHERE a = -1 AND b = "x" AND c = b"y" AND d = 1.5 AND e = DATE "2020-01-02" ` +
+			`AND f = TIMESTAMP "2020-01-02T03:04:05Z" AND g = NUMERIC "1.25" AND h = JSON '{"a": 1}'`
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("difference in result: (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("missing parameter", func(t *testing.T) {
+		if _, err := gsqlutils.InlineParameters("", "SELECT @unknown", params); err == nil {
+			t.Error("should fail, but success")
+		}
+	})
+}
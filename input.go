@@ -0,0 +1,165 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/samber/lo"
+)
+
+// InputBuffer accumulates interactive input line by line, and detects complete statements.
+// It only keeps the input which is not yet returned as statements.
+// A pending statement continued by a line is not lexed again from its head, lexing resumes from its last token.
+type InputBuffer struct {
+	sp *separator
+
+	// pending is the input which is not yet returned as statements.
+	pending strings.Builder
+
+	// base is the location of pending in the whole input.
+	base Location
+
+	// rp is the resume point of the pending statement at the head of pending.
+	rp resumePoint
+}
+
+// InputStatus is a status of InputBuffer after a line is appended.
+type InputStatus struct {
+	// Statements are statements completed by the appended line.
+	Statements []RawStatement
+
+	// WaitingString is the string to close the innermost unclosed construct, e.g. `"""`, `'''`, `*/`, `)`.
+	// It is empty if no construct is unclosed.
+	WaitingString string
+
+	// Unclosed is the opening string of the innermost unclosed construct, e.g. `r"""`, `/*`, `(`, `@{`.
+	// It is empty if no construct is unclosed.
+	Unclosed string
+
+	// Open is the stack of unclosed constructs in the pending statement, the outermost first.
+	// Locations are in the whole input since the last Reset.
+	Open []OpenConstruct
+
+	// Pending is the rest of input which is not yet a complete statement.
+	Pending string
+
+	// Err is the error of the pending statement which can't be a valid statement by more lines.
+	// The pending statement is discarded, but Statements completed before it are still valid.
+	Err error
+}
+
+// NewInputBuffer creates a new empty InputBuffer.
+// filepath can be empty, it is only used in error message.
+func NewInputBuffer(filepath string, opts ...SeparateOption) *InputBuffer {
+	sp := newSeparator(filepath, opts...)
+	sp.keepTokens = true
+	return &InputBuffer{sp: sp}
+}
+
+// AppendLine appends a line to the buffer and returns the current status.
+// A newline is appended if line doesn't end with it.
+// If the pending statement can't be a valid statement by more lines, it is discarded and the error is reported as Err of the status.
+// Statements completed before it and the delimiter set by DELIMITER command are kept.
+// Pos and End of statements are offsets from the beginning of the whole input since the last Reset.
+func (b *InputBuffer) AppendLine(line string) *InputStatus {
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	b.pending.WriteString(line)
+
+	// Leading whitespaces are not a part of the next statement.
+	s := b.pending.String()
+	if trimmed := strings.TrimLeftFunc(s, unicode.IsSpace); len(trimmed) < len(s) {
+		b.base = advanceLocation(b.base, s[:len(s)-len(trimmed)])
+		s = trimmed
+
+		b.pending.Reset()
+		b.pending.WriteString(s)
+	}
+
+	result, err := b.sp.separateFrom(s, b.rp, false)
+	if result.consumed > 0 {
+		b.sp.delimiter = result.delimiter
+	}
+
+	status := &InputStatus{}
+	for _, stmt := range result.statements {
+		if stmt.Terminator == "" {
+			break
+		}
+		status.Statements = append(status.Statements, shiftRawStatement(stmt, b.base.Pos))
+	}
+
+	lexerStatus, isLexerStatus := lo.ErrorsAs[*ErrLexerStatus](shiftError(err, b.base))
+	switch {
+	case err == nil:
+		if last := len(result.statements) - 1; last >= 0 && result.statements[last].Terminator == "" {
+			status.Open = lo.Map(openBrackets(result.tokens[last], lazyPositionIndex(s)), func(c OpenConstruct, _ int) OpenConstruct {
+				c.Location = shiftLocation(c.Location, b.base)
+				return c
+			})
+		}
+	case isLexerStatus:
+		status.Open = lexerStatus.Open
+	default:
+		status.Err = fmt.Errorf("invalid input, err: %w", shiftError(err, b.base))
+
+		b.base = advanceLocation(b.base, s)
+		b.pending.Reset()
+		b.rp = resumePoint{}
+		return status
+	}
+
+	if top, ok := lo.Last(status.Open); ok {
+		status.Unclosed, status.WaitingString = top.Opening, top.Closing
+	}
+
+	// Cut the buffer before the pending statement, it is resumed by the next line.
+	cut := token.Pos(len(s))
+	b.rp = resumePoint{}
+	if last, ok := lo.Last(result.statements); ok && last.Terminator == "" {
+		cut, b.rp = last.Pos, result.resume.rebase(s, last.Pos)
+	}
+
+	if cut > 0 {
+		b.base = advanceLocation(b.base, s[:cut])
+		b.pending.Reset()
+		b.pending.WriteString(s[cut:])
+	}
+
+	status.Pending = b.pending.String()
+	return status
+}
+
+// Pending returns the input which is not yet returned as statements.
+func (b *InputBuffer) Pending() string {
+	return b.pending.String()
+}
+
+// Reset discards the pending input and resets positions and the delimiter.
+func (b *InputBuffer) Reset() {
+	b.pending.Reset()
+	b.base = Location{}
+	b.rp = resumePoint{}
+	b.sp.delimiter = defaultDelimiter
+}
+
+// IsPending returns true if there is an incomplete statement.
+func (s *InputStatus) IsPending() bool {
+	return s.Pending != ""
+}
+
+// Prompt returns a prompt for the next line.
+// It returns primary if no statement is pending, otherwise it returns a continuation prompt like `*/>` or `->`
+// right-aligned to the width of primary.
+func (s *InputStatus) Prompt(primary string) string {
+	if !s.IsPending() {
+		return primary
+	}
+
+	prompt := lo.Ternary(s.WaitingString != "", s.WaitingString, "-") + "> "
+	return strings.Repeat(" ", max(0, utf8.RuneCountInString(primary)-utf8.RuneCountInString(prompt))) + prompt
+}
@@ -0,0 +1,122 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInputBuffer(t *testing.T) {
+	type step struct {
+		line           string
+		wantStatements []string
+		wantWaiting    string
+		wantUnclosed   string
+		wantPrompt     string
+		wantErr        bool
+	}
+
+	const primary = "spanner> "
+
+	for _, tt := range []struct {
+		desc  string
+		opts  []gsqlutils.SeparateOption
+		steps []step
+	}{
+		{
+			desc: "single line",
+			steps: []step{
+				{line: "SELECT 1;", wantStatements: []string{"SELECT 1"}, wantPrompt: primary},
+			},
+		},
+		{
+			desc: "multiple lines",
+			steps: []step{
+				{line: "SELECT 1,", wantPrompt: "      -> "},
+				{line: "2; SELECT", wantStatements: []string{"SELECT 1,\n2"}, wantPrompt: "      -> "},
+				{line: "3;", wantStatements: []string{"SELECT\n3"}, wantPrompt: primary},
+			},
+		},
+		{
+			desc: "triple-quoted string",
+			steps: []step{
+				{line: `SELECT """a;`, wantWaiting: `"""`, wantUnclosed: `"""`, wantPrompt: `    """> `},
+				{line: `b""";`, wantStatements: []string{"SELECT \"\"\"a;\nb\"\"\""}, wantPrompt: primary},
+			},
+		},
+		{
+			desc: "comment",
+			steps: []step{
+				{line: `SELECT /* ;`, wantWaiting: `*/`, wantUnclosed: `/*`, wantPrompt: "     */> "},
+				{line: `*/ 1;`, wantStatements: []string{"SELECT /* ;\n*/ 1"}, wantPrompt: primary},
+			},
+		},
+		{
+			desc: "brackets and hints",
+			steps: []step{
+				{line: `SELECT (`, wantWaiting: `)`, wantUnclosed: `(`, wantPrompt: "      )> "},
+				{line: `[1, 2`, wantWaiting: `]`, wantUnclosed: `[`, wantPrompt: "      ]> "},
+				{line: `])`, wantPrompt: "      -> "},
+				{line: `FROM t@{`, wantWaiting: `}`, wantUnclosed: `@{`, wantPrompt: "      }> "},
+				{line: `FORCE_INDEX=_BASE_TABLE};`, wantStatements: []string{"SELECT (\n[1, 2\n])\nFROM t@{\nFORCE_INDEX=_BASE_TABLE}"}, wantPrompt: primary},
+			},
+		},
+		{
+			desc: "compound type and raw string",
+			steps: []step{
+				{line: `SELECT CAST(NULL AS ARRAY<`, wantWaiting: `>`, wantUnclosed: `ARRAY<`, wantPrompt: "      >> "},
+				{line: `STRING>), r"""a`, wantWaiting: `"""`, wantUnclosed: `r"""`, wantPrompt: `    """> `},
+				{line: `""";`, wantStatements: []string{"SELECT CAST(NULL AS ARRAY<\nSTRING>), r\"\"\"a\n\"\"\""}, wantPrompt: primary},
+			},
+		},
+		{
+			desc: "invalid input",
+			steps: []step{
+				{line: `SELECT 1; SELECT 'a`, wantStatements: []string{"SELECT 1"}, wantPrompt: primary, wantErr: true},
+				{line: `SELECT 2;`, wantStatements: []string{"SELECT 2"}, wantPrompt: primary},
+			},
+		},
+		{
+			desc: "invalid input keeps delimiter",
+			opts: []gsqlutils.SeparateOption{gsqlutils.WithDelimiterCommand()},
+			steps: []step{
+				{line: `DELIMITER $$`, wantPrompt: primary},
+				{line: `SELECT 1$$ SELECT 'a`, wantStatements: []string{"SELECT 1"}, wantPrompt: primary, wantErr: true},
+				{line: `SELECT 2;`, wantPrompt: "      -> "},
+				{line: `SELECT 3$$`, wantStatements: []string{"SELECT 2;\nSELECT 3"}, wantPrompt: primary},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			b := gsqlutils.NewInputBuffer("", tt.opts...)
+			for i, step := range tt.steps {
+				status := b.AppendLine(step.line)
+				if step.wantErr {
+					if status.Err == nil {
+						t.Errorf("step %d: should fail, but success", i)
+					}
+				} else if status.Err != nil {
+					t.Fatalf("step %d: should success, but failed: %v", i, status.Err)
+				}
+
+				var got []string
+				for _, stmt := range status.Statements {
+					got = append(got, stmt.Statement)
+				}
+				if diff := cmp.Diff(step.wantStatements, got); diff != "" {
+					t.Errorf("step %d: difference in statements: (-want +got):\n%s", i, diff)
+				}
+				if status.WaitingString != step.wantWaiting {
+					t.Errorf("step %d: WaitingString = %q, want %q", i, status.WaitingString, step.wantWaiting)
+				}
+				if status.Unclosed != step.wantUnclosed {
+					t.Errorf("step %d: Unclosed = %q, want %q", i, status.Unclosed, step.wantUnclosed)
+				}
+				if got := status.Prompt(primary); got != step.wantPrompt {
+					t.Errorf("step %d: Prompt() = %q, want %q", i, got, step.wantPrompt)
+				}
+			}
+		})
+	}
+}
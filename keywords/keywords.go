@@ -0,0 +1,119 @@
+// Package keywords is the table of GoogleSQL keywords shared by transforms in gsqlutils.
+package keywords
+
+import (
+	"slices"
+
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// reserved are reserved keywords of GoogleSQL in Spanner, they can't be unquoted identifiers.
+// https://cloud.google.com/spanner/docs/reference/standard-sql/lexical#reserved_keywords
+var reserved = []string{
+	"ALL", "AND", "ANY", "ARRAY", "AS", "ASC", "ASSERT_ROWS_MODIFIED", "AT",
+	"BETWEEN", "BY",
+	"CASE", "CAST", "COLLATE", "CONTAINS", "CREATE", "CROSS", "CUBE", "CURRENT",
+	"DEFAULT", "DEFINE", "DESC", "DISTINCT",
+	"ELSE", "END", "ENUM", "ESCAPE", "EXCEPT", "EXCLUDE", "EXISTS", "EXTRACT",
+	"FALSE", "FETCH", "FOLLOWING", "FOR", "FROM", "FULL",
+	"GRAPH_TABLE", "GROUP", "GROUPING", "GROUPS",
+	"HASH", "HAVING",
+	"IF", "IGNORE", "IN", "INNER", "INTERSECT", "INTERVAL", "INTO", "IS",
+	"JOIN",
+	"LATERAL", "LEFT", "LIKE", "LIMIT", "LOOKUP",
+	"MERGE",
+	"NATURAL", "NEW", "NO", "NOT", "NULL", "NULLS",
+	"OF", "ON", "OR", "ORDER", "OUTER", "OVER",
+	"PARTITION", "PRECEDING", "PROTO",
+	"RANGE", "RECURSIVE", "RESPECT", "RIGHT", "ROLLUP", "ROWS",
+	"SELECT", "SET", "SOME", "STRUCT",
+	"TABLESAMPLE", "THEN", "TO", "TREAT", "TRUE",
+	"UNBOUNDED", "UNION", "UNNEST", "USING",
+	"WHEN", "WHERE", "WINDOW", "WITH", "WITHIN",
+}
+
+var reservedMap = func() map[string]struct{} {
+	m := make(map[string]struct{}, len(reserved))
+	for _, k := range reserved {
+		m[k] = struct{}{}
+	}
+	return m
+}()
+
+// Reserved returns reserved keywords in upper case and alphabetical order.
+func Reserved() []string {
+	return slices.Clone(reserved)
+}
+
+// IsReserved is true if s is a reserved keyword case-insensitively.
+func IsReserved(s string) bool {
+	_, ok := reservedMap[char.ToUpper(s)]
+	return ok
+}
+
+// IsKeywordToken is true if tok is a reserved keyword token.
+func IsKeywordToken(tok token.Token) bool {
+	_, ok := reservedMap[string(tok.Kind)]
+	return ok
+}
+
+// Match is true if tok is keyword, which can be a reserved keyword, a non-reserved keyword like INSERT, or a symbol like "(".
+// A non-reserved keyword matches an unquoted identifier case-insensitively, and others match by the token kind.
+// Quoted identifiers never match.
+func Match(tok token.Token, keyword string) bool {
+	if tok.Kind == token.TokenIdent {
+		return tok.IsKeywordLike(keyword)
+	}
+	return string(tok.Kind) == char.ToUpper(keyword)
+}
+
+// MatchAny is true if tok matches one of keywords by Match.
+func MatchAny(tok token.Token, keywords ...string) bool {
+	for _, k := range keywords {
+		if Match(tok, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// First tokens of statements by kind, they are matched by Match.
+var (
+	// Current prefixes of DDL statements
+	// https://cloud.google.com/spanner/docs/reference/standard-sql/data-definition-language
+	ddlFirstTokens = []string{"CREATE", "ALTER", "DROP", "RENAME", "GRANT", "REVOKE", "ANALYZE"}
+	dmlFirstTokens = []string{"INSERT", "DELETE", "UPDATE"}
+
+	// It starts with "WITH" of CTE or query expression, it can be a ZetaSQL FROM query.
+	// https://cloud.google.com/spanner/docs/reference/standard-sql/query-syntax#sql_syntax
+	// https://github.com/google/zetasql/blob/master/docs/pipe-syntax.md#from-queries
+	queryFirstTokens = []string{"SELECT", "WITH", "(", "FROM"}
+	graphFirstTokens = []string{"GRAPH"}
+	callFirstTokens  = []string{"CALL"}
+)
+
+// DDLFirstTokens returns first tokens of DDL statements.
+func DDLFirstTokens() []string {
+	return slices.Clone(ddlFirstTokens)
+}
+
+// DMLFirstTokens returns first tokens of DML statements.
+func DMLFirstTokens() []string {
+	return slices.Clone(dmlFirstTokens)
+}
+
+// QueryFirstTokens returns first tokens of query statements.
+func QueryFirstTokens() []string {
+	return slices.Clone(queryFirstTokens)
+}
+
+// GraphFirstTokens returns first tokens of graph query statements.
+func GraphFirstTokens() []string {
+	return slices.Clone(graphFirstTokens)
+}
+
+// CallFirstTokens returns first tokens of CALL statements.
+func CallFirstTokens() []string {
+	return slices.Clone(callFirstTokens)
+}
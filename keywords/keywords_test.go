@@ -0,0 +1,65 @@
+package keywords_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/apstndb/gsqlutils/keywords"
+)
+
+// TestReservedMatchesLexer guards the table against keywords of the lexer.
+func TestReservedMatchesLexer(t *testing.T) {
+	reserved := keywords.Reserved()
+	if !slices.IsSorted(reserved) {
+		t.Error("Reserved should be sorted")
+	}
+
+	for _, k := range reserved {
+		if _, ok := token.KeywordsMap[token.TokenKind(k)]; !ok {
+			t.Errorf("%v is not a keyword of the lexer", k)
+		}
+	}
+	for _, k := range token.Keywords {
+		if !keywords.IsReserved(string(k)) {
+			t.Errorf("%v is a keyword of the lexer, but not reserved", k)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tok, err := gsqlutils.FirstNonHintToken("", "select 1")
+	if err != nil {
+		t.Fatalf("should success, but failed: %v", err)
+	}
+	if !keywords.Match(tok, "SELECT") || !keywords.Match(tok, "select") || keywords.Match(tok, "WITH") {
+		t.Errorf("reserved keyword should match by its kind: %v", tok.Raw)
+	}
+
+	for _, tt := range []struct {
+		input string
+		want  bool
+	}{
+		{input: "insert INTO t", want: true},
+		{input: "`INSERT` INTO t", want: false},
+		{input: "(SELECT 1)", want: false},
+	} {
+		tok, err := gsqlutils.FirstNonHintToken("", tt.input)
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+		if got := keywords.Match(tok, "INSERT"); got != tt.want {
+			t.Errorf("%v: want %v, but got %v", tt.input, tt.want, got)
+		}
+	}
+
+	tok, err = gsqlutils.FirstNonHintToken("", "(SELECT 1)")
+	if err != nil {
+		t.Fatalf("should success, but failed: %v", err)
+	}
+	if !keywords.MatchAny(tok, "SELECT", "(") {
+		t.Errorf("symbol should match by its kind: %v", tok.Raw)
+	}
+}
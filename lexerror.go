@@ -0,0 +1,118 @@
+package gsqlutils
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// Sentinel errors of LexerError. They can be checked by errors.Is.
+var (
+	// ErrUnclosedString is an unclosed string or bytes literal, including triple-quoted ones.
+	ErrUnclosedString = errors.New("unclosed string literal")
+
+	// ErrUnclosedIdentifier is an unclosed quoted identifier.
+	ErrUnclosedIdentifier = errors.New("unclosed identifier")
+
+	// ErrEmptyIdentifier is an empty quoted identifier.
+	ErrEmptyIdentifier = errors.New("empty identifier")
+
+	// ErrUnclosedComment is an unclosed block comment.
+	ErrUnclosedComment = errors.New("unclosed comment")
+
+	// ErrInvalidEscape is an invalid escape sequence in a literal or a quoted identifier.
+	ErrInvalidEscape = errors.New("invalid escape sequence")
+
+	// ErrUnexpectedEOF is an input which ends in the middle of an escape sequence.
+	ErrUnexpectedEOF = errors.New("unexpected EOF")
+
+	// ErrInvalidNumber is a number literal followed by identifier characters, e.g. `1x`.
+	ErrInvalidNumber = errors.New("invalid number literal")
+
+	// ErrIllegalCharacter is a character which can't start any token.
+	ErrIllegalCharacter = errors.New("illegal character")
+)
+
+// LexerError is a classified memefish.Error.
+// errors.Is matches Kind, and errors.As matches *memefish.Error.
+type LexerError struct {
+	// Kind is one of sentinel errors like ErrUnclosedString.
+	Kind error
+
+	Err *memefish.Error
+}
+
+func (e *LexerError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LexerError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// ClassifyLexerError classifies err returned by lexing s into LexerError.
+// tokPos is the position of the token which the lexer was reading, it is the position of the token yielded with err by LexerSeq.
+// The category is decided by the input at the positions of err and the token, not by the error message.
+// It returns err as is if err is not a *memefish.Error or can't be classified.
+func ClassifyLexerError(s string, tokPos token.Pos, err error) error {
+	merr, ok := err.(*memefish.Error)
+	if !ok || merr.Position == nil {
+		return err
+	}
+
+	if kind := classifyLexerError(s, tokPos, merr.Position.Pos, merr.Position.End); kind != nil {
+		return &LexerError{Kind: kind, Err: merr}
+	}
+	return err
+}
+
+func classifyLexerError(s string, tokPos, pos, end token.Pos) error {
+	if pos.Invalid() || int(pos) > len(s) {
+		return nil
+	}
+	head := s[pos:]
+
+	switch {
+	// The lexer resets the token on an error in comments, so tokPos can't be used.
+	case strings.HasPrefix(head, "/*"):
+		return ErrUnclosedComment
+	case strings.HasPrefix(head, `\`) && len(head) == 1:
+		return ErrUnexpectedEOF
+	case strings.HasPrefix(head, `\`):
+		return ErrInvalidEscape
+	}
+
+	if tokPos.Invalid() || tokPos > pos {
+		return nil
+	}
+	tokHead := trimLiteralPrefix(s[tokPos:])
+	if len(tokHead) == 0 {
+		return nil
+	}
+
+	switch c := tokHead[0]; {
+	case c == '\'' || c == '"':
+		return ErrUnclosedString
+	case c == '`' && strings.HasPrefix(head, "``") && end-pos == 2:
+		return ErrEmptyIdentifier
+	case c == '`':
+		return ErrUnclosedIdentifier
+	case char.IsDigit(c) || c == '.' && len(tokHead) > 1 && char.IsDigit(tokHead[1]):
+		return ErrInvalidNumber
+	case tokPos == pos && pos == end:
+		return ErrIllegalCharacter
+	default:
+		return nil
+	}
+}
+
+// trimLiteralPrefix trims r and b prefixes of a string or bytes literal.
+func trimLiteralPrefix(s string) string {
+	for i := 0; i < 2 && len(s) > 0 && strings.ContainsRune("rRbB", rune(s[0])); i++ {
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+package gsqlutils_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+// TestClassifyLexerError also guards assumptions on the upstream lexer.
+// If it fails after upgrading memefish, the classification needs to be updated.
+func TestClassifyLexerError(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		input   string
+		want    error
+		wantPos token.Pos
+	}{
+		{desc: "unclosed string", input: "SELECT 'abc", want: gsqlutils.ErrUnclosedString, wantPos: 7},
+		{desc: "newline in string", input: "SELECT \"abc\nx\"", want: gsqlutils.ErrUnclosedString, wantPos: 7},
+		{desc: "unclosed triple-quoted string", input: `SELECT """abc`, want: gsqlutils.ErrUnclosedString, wantPos: 7},
+		{desc: "unclosed raw triple-quoted string", input: "SELECT r'''abc", want: gsqlutils.ErrUnclosedString, wantPos: 8},
+		{desc: "unclosed raw bytes", input: `SELECT rb"""abc`, want: gsqlutils.ErrUnclosedString, wantPos: 9},
+		{desc: "unclosed identifier", input: "SELECT `abc", want: gsqlutils.ErrUnclosedIdentifier, wantPos: 7},
+		{desc: "empty identifier", input: "SELECT ``", want: gsqlutils.ErrEmptyIdentifier, wantPos: 7},
+		{desc: "unclosed comment", input: "SELECT 1 /* abc", want: gsqlutils.ErrUnclosedComment, wantPos: 9},
+		{desc: "invalid escape", input: `SELECT '\q'`, want: gsqlutils.ErrInvalidEscape, wantPos: 8},
+		{desc: "invalid hex escape", input: `SELECT b'\xZZ'`, want: gsqlutils.ErrInvalidEscape, wantPos: 9},
+		{desc: "invalid escape in identifier", input: "SELECT `\\q`", want: gsqlutils.ErrInvalidEscape, wantPos: 8},
+		{desc: "escape at EOF", input: `SELECT 'abc\`, want: gsqlutils.ErrUnexpectedEOF, wantPos: 11},
+		{desc: "escape at EOF in triple-quoted string", input: `SELECT '''abc\`, want: gsqlutils.ErrUnexpectedEOF, wantPos: 13},
+		{desc: "invalid number", input: "SELECT 123abc", want: gsqlutils.ErrInvalidNumber, wantPos: 10},
+		{desc: "invalid hex number", input: "SELECT 0x1G", want: gsqlutils.ErrInvalidNumber, wantPos: 10},
+		{desc: "invalid exponent", input: "SELECT 1e+", want: gsqlutils.ErrInvalidNumber, wantPos: 8},
+		{desc: "illegal control character", input: "SELECT \x01", want: gsqlutils.ErrIllegalCharacter, wantPos: 7},
+		{desc: "illegal non-ASCII character", input: "SELECT ！", want: gsqlutils.ErrIllegalCharacter, wantPos: 7},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var err error
+			for _, lexErr := range gsqlutils.NewLexerSeq("", tt.input) {
+				err = lexErr
+			}
+			if err == nil {
+				t.Fatal("should fail, but success")
+			}
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error should be %v, but got: %v", tt.want, err)
+			}
+
+			var lerr *gsqlutils.LexerError
+			if !errors.As(err, &lerr) {
+				t.Fatalf("error should be *gsqlutils.LexerError, but got: %T", err)
+			}
+
+			var merr *memefish.Error
+			if !errors.As(err, &merr) {
+				t.Fatalf("error should wrap *memefish.Error, but got: %T", err)
+			}
+			if merr.Position.Pos != tt.wantPos {
+				t.Errorf("position of the upstream error is changed, want: %v, got: %v", tt.wantPos, merr.Position.Pos)
+			}
+		})
+	}
+}
+
+func TestLexerSeq_MemefishError(t *testing.T) {
+	lexer := &memefish.Lexer{File: &token.File{Buffer: "SELECT 'abc"}}
+
+	var err error
+	for _, lexErr := range gsqlutils.LexerSeq(lexer) {
+		err = lexErr
+	}
+
+	var merr *memefish.Error
+	if !errors.As(err, &merr) {
+		t.Fatalf("error should wrap *memefish.Error, but got: %T", err)
+	}
+	if merr.Position.Pos != 7 {
+		t.Errorf("want position 7, but got %v", merr.Position.Pos)
+	}
+}
+
+func TestSeparateInput_LexerError(t *testing.T) {
+	// Errors are still classified after shifting positions by terminators.
+	_, err := gsqlutils.SeparateInput("", "SELECT 1;\nSELECT '\\q';")
+	if !errors.Is(err, gsqlutils.ErrInvalidEscape) {
+		t.Errorf("error should be %v, but got: %v", gsqlutils.ErrInvalidEscape, err)
+	}
+
+	_, err = gsqlutils.SeparateInput("", "SELECT 1;\nSELECT 'abc")
+	if !errors.Is(err, gsqlutils.ErrUnclosedString) {
+		t.Errorf("error should be %v, but got: %v", gsqlutils.ErrUnclosedString, err)
+	}
+
+	var status *gsqlutils.ErrLexerStatus
+	if errors.As(err, &status) {
+		t.Errorf("unclosed single-quoted string can't be continued, but got: %v", status)
+	}
+}
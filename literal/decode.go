@@ -0,0 +1,290 @@
+package literal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// Error is an error on decoding a literal.
+// Pos and End are byte offsets in the literal text, e.g. the offset of the invalid escape sequence.
+// If a literal is taken from a token, the position in the whole input is Pos + token.Pos.
+type Error struct {
+	Pos, End token.Pos
+	Message  string
+
+	// Err is the lexer error if the literal text can't be lexed.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v at offset %v", e.Message, e.Pos)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// defaultTimeZone is the default time zone of TIMESTAMP literals without time zones in Spanner.
+const defaultTimeZone = "America/Los_Angeles"
+
+// Decode decodes a GoogleSQL literal text to a Go value.
+// Surrounding whitespaces are allowed, but comments are not.
+//
+// The results are:
+//   - int64 for INT64 literals, including hexadecimal ones and a unary minus
+//   - float64 for FLOAT64 literals
+//   - string and []byte for STRING and BYTES literals in all quoting forms, e.g. r"""...""" and b'...'
+//   - bool for TRUE and FALSE, and nil for NULL
+//   - time.Time for DATE '...' at midnight in UTC, and for TIMESTAMP '...'
+//   - *big.Rat for NUMERIC '...'
+//   - json.RawMessage for JSON '...'
+//
+// The returned error is *Error with the position of the error.
+func Decode(s string) (any, error) {
+	tokens, err := lexLiteral(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(tokens) == 1:
+		return decodeToken(tokens[0], false)
+	case len(tokens) == 2 && tokens[0].Kind == "-" && (tokens[1].Kind == token.TokenInt || tokens[1].Kind == token.TokenFloat):
+		return decodeToken(tokens[1], true)
+	case len(tokens) == 2 && tokens[0].Kind == token.TokenIdent && tokens[1].Kind == token.TokenString:
+		return decodeTyped(tokens[0], tokens[1])
+	case len(tokens) == 0:
+		return nil, &Error{Pos: 0, End: token.Pos(len(s)), Message: "empty literal"}
+	default:
+		return nil, &Error{Pos: tokens[0].Pos, End: tokens[len(tokens)-1].End, Message: "not a literal"}
+	}
+}
+
+// DecodeString decodes a STRING literal text in any quoting form.
+func DecodeString(s string) (string, error) {
+	tok, err := lexSingleLiteral(s, token.TokenString)
+	if err != nil {
+		return "", err
+	}
+	return tok.AsString, nil
+}
+
+// DecodeBytes decodes a BYTES literal text in any quoting form.
+func DecodeBytes(s string) ([]byte, error) {
+	tok, err := lexSingleLiteral(s, token.TokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(tok.AsString), nil
+}
+
+// DecodeInt64 decodes an INT64 literal text, including hexadecimal ones and a unary minus.
+func DecodeInt64(s string) (int64, error) {
+	v, err := Decode(s)
+	if err != nil {
+		return 0, err
+	}
+	i, ok := v.(int64)
+	if !ok {
+		return 0, &Error{Pos: 0, End: token.Pos(len(s)), Message: "not an INT64 literal"}
+	}
+	return i, nil
+}
+
+// DecodeFloat64 decodes a FLOAT64 literal text with a unary minus. INT64 literals are also accepted.
+func DecodeFloat64(s string) (float64, error) {
+	v, err := Decode(s)
+	if err != nil {
+		return 0, err
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	default:
+		return 0, &Error{Pos: 0, End: token.Pos(len(s)), Message: "not a FLOAT64 literal"}
+	}
+}
+
+// lexLiteral lexes s without comments.
+func lexLiteral(s string) ([]token.Token, error) {
+	lexer := &memefish.Lexer{File: &token.File{Buffer: s}}
+
+	var tokens []token.Token
+	for {
+		if err := lexer.NextToken(); err != nil {
+			if merr, ok := err.(*memefish.Error); ok && merr.Position != nil {
+				return nil, &Error{Pos: merr.Position.Pos, End: merr.Position.End, Message: merr.Message, Err: merr}
+			}
+			return nil, &Error{Pos: lexer.Token.Pos, End: lexer.Token.End, Message: err.Error(), Err: err}
+		}
+
+		if len(lexer.Token.Comments) > 0 {
+			c := lexer.Token.Comments[0]
+			return nil, &Error{Pos: c.Pos, End: c.End, Message: "comments are not allowed in literals"}
+		}
+
+		if lexer.Token.Kind == token.TokenEOF {
+			return tokens, nil
+		}
+		tokens = append(tokens, lexer.Token)
+	}
+}
+
+func lexSingleLiteral(s string, kind token.TokenKind) (token.Token, error) {
+	tokens, err := lexLiteral(s)
+	if err != nil {
+		return token.Token{}, err
+	}
+	if len(tokens) != 1 || tokens[0].Kind != kind {
+		return token.Token{}, &Error{Pos: 0, End: token.Pos(len(s)), Message: fmt.Sprintf("not a single %v literal", kind)}
+	}
+	return tokens[0], nil
+}
+
+func decodeToken(tok token.Token, negative bool) (any, error) {
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+
+	switch tok.Kind {
+	case token.TokenInt:
+		v, err := parseInt(sign + tok.Raw)
+		if err != nil {
+			return nil, &Error{Pos: tok.Pos, End: tok.End, Message: "INT64 literal out of range", Err: err}
+		}
+		return v, nil
+	case token.TokenFloat:
+		v, err := strconv.ParseFloat(sign+tok.Raw, 64)
+		if err != nil {
+			return nil, &Error{Pos: tok.Pos, End: tok.End, Message: "invalid FLOAT64 literal", Err: err}
+		}
+		return v, nil
+	case token.TokenString:
+		if !utf8.ValidString(tok.AsString) {
+			return nil, &Error{Pos: tok.Pos, End: tok.End, Message: "STRING literal is not valid UTF-8"}
+		}
+		return tok.AsString, nil
+	case token.TokenBytes:
+		return []byte(tok.AsString), nil
+	case "TRUE", "FALSE":
+		return tok.Kind == "TRUE", nil
+	case "NULL":
+		return nil, nil
+	default:
+		return nil, &Error{Pos: tok.Pos, End: tok.End, Message: fmt.Sprintf("not a literal: %v", tok.Raw)}
+	}
+}
+
+// parseInt parses a decimal or hexadecimal integer literal with an optional sign.
+// Unlike strconv.ParseInt with base 0, leading zeros don't mean octal.
+func parseInt(s string) (int64, error) {
+	sign, digits := "", s
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	if hex, ok := strings.CutPrefix(strings.ToLower(digits), "0x"); ok {
+		return strconv.ParseInt(sign+hex, 16, 64)
+	}
+	return strconv.ParseInt(sign+digits, 10, 64)
+}
+
+func decodeTyped(typ, value token.Token) (any, error) {
+	invalid := func(err error) error {
+		return &Error{Pos: value.Pos, End: value.End, Message: fmt.Sprintf("invalid %v literal", char.ToUpper(typ.Raw)), Err: err}
+	}
+
+	switch {
+	case typ.IsKeywordLike("DATE"):
+		t, err := time.Parse("2006-1-2", value.AsString)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		return t, nil
+	case typ.IsKeywordLike("TIMESTAMP"):
+		t, err := parseTimestamp(value.AsString)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		return t, nil
+	case typ.IsKeywordLike("NUMERIC"):
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(value.AsString))
+		if !ok || strings.Contains(value.AsString, "/") {
+			return nil, invalid(nil)
+		}
+		return r, nil
+	case typ.IsKeywordLike("JSON"):
+		if !json.Valid([]byte(value.AsString)) {
+			return nil, invalid(nil)
+		}
+		return json.RawMessage(value.AsString), nil
+	default:
+		return nil, &Error{Pos: typ.Pos, End: typ.End, Message: fmt.Sprintf("unknown typed literal: %v", typ.Raw)}
+	}
+}
+
+// timestampOffsetLayouts are layouts of the canonical timestamp format with offsets or "Z".
+// "T" is replaced with " " before parsing.
+var timestampOffsetLayouts = []string{
+	"2006-1-2 15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999Z07",
+}
+
+// timestampLocalLayouts are layouts of the canonical timestamp format without offsets.
+var timestampLocalLayouts = []string{
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2",
+}
+
+// parseTimestamp parses a TIMESTAMP literal value.
+// The time zone can be an offset, "Z", or a time zone name separated by a whitespace, and the default is America/Los_Angeles.
+// Time zone names are loaded only if they are needed, so timestamps with offsets can be parsed without tzdata.
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 10 && (s[10] == 'T' || s[10] == 't') {
+		s = s[:10] + " " + s[11:]
+	}
+
+	zone := ""
+	if i := strings.LastIndexByte(s, ' '); i > 0 && (strings.Contains(s[i+1:], "/") || char.EqualFold(s[i+1:], "UTC")) {
+		s, zone = s[:i], s[i+1:]
+	}
+
+	var lastErr error
+	if zone == "" {
+		for _, layout := range timestampOffsetLayouts {
+			t, err := time.Parse(layout, s)
+			if err == nil {
+				return t, nil
+			}
+			lastErr = err
+		}
+		zone = defaultTimeZone
+	}
+
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range timestampLocalLayouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
@@ -0,0 +1,109 @@
+package literal_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils/literal"
+)
+
+func TestDecode(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  any
+	}{
+		{desc: "int", input: "42", want: int64(42)},
+		{desc: "negative int", input: "-42", want: int64(-42)},
+		{desc: "hex int", input: "0x1F", want: int64(31)},
+		{desc: "leading zeros", input: "010", want: int64(10)},
+		{desc: "min int64", input: "-9223372036854775808", want: int64(-9223372036854775808)},
+		{desc: "float", input: " 1.5e3 ", want: 1500.0},
+		{desc: "negative float", input: "- .5", want: -0.5},
+		{desc: "string", input: `'a\n\x41\u00e9'`, want: "a\nAé"},
+		{desc: "raw triple-quoted string", input: `r'''a\n'''`, want: `a\n`},
+		{desc: "bytes", input: `b"\x00"`, want: []byte{0}},
+		{desc: "raw bytes", input: `RB'\d'`, want: []byte(`\d`)},
+		{desc: "bool", input: "true", want: true},
+		{desc: "null", input: "NULL", want: nil},
+		{desc: "date", input: "DATE '2020-1-2'", want: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{desc: "timestamp", input: `timestamp "2020-01-02T03:04:05.6+09:00"`, want: time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.FixedZone("", 9*60*60))},
+		{desc: "timestamp with zone name", input: "TIMESTAMP '2020-01-02 03:04:05 UTC'", want: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{desc: "timestamp with Z", input: "TIMESTAMP '2020-01-02T03:04:05Z'", want: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{desc: "timestamp in default zone", input: "TIMESTAMP '2020-01-02 03:04:05'", want: time.Date(2020, 1, 2, 11, 4, 5, 0, time.UTC)},
+		{desc: "numeric", input: "NUMERIC '-1.25'", want: big.NewRat(-5, 4)},
+		{desc: "json", input: `JSON '{"a": 1}'`, want: json.RawMessage(`{"a": 1}`)},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := literal.Decode(tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) }), cmp.Comparer(func(a, b *big.Rat) bool { return a.Cmp(b) == 0 })); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		desc    string
+		input   string
+		wantPos int
+	}{
+		{desc: "invalid escape", input: `'ab\qc'`, wantPos: 3},
+		{desc: "unclosed raw triple-quoted string", input: `r'''abc`, wantPos: 1},
+		{desc: "int out of range", input: "  9223372036854775808", wantPos: 2},
+		{desc: "invalid date", input: "DATE '2020-13-01'", wantPos: 5},
+		{desc: "timestamp without seconds", input: "TIMESTAMP '2020-01-02 03:04'", wantPos: 10},
+		{desc: "invalid numeric", input: "NUMERIC '1/2'", wantPos: 8},
+		{desc: "unknown typed literal", input: "INTERVAL '1'", wantPos: 0},
+		{desc: "not a literal", input: "a + 1", wantPos: 0},
+		{desc: "comment", input: "1 /* c */", wantPos: 2},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := literal.Decode(tt.input)
+			var lerr *literal.Error
+			if !errors.As(err, &lerr) {
+				t.Fatalf("should fail with *literal.Error, but got: %v", err)
+			}
+			if int(lerr.Pos) != tt.wantPos {
+				t.Errorf("want error at %v, but got at %v: %v", tt.wantPos, lerr.Pos, lerr)
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	for _, s := range []string{"", "it's", `C:\path`, "a\nb", "a\\\n'\"", "\x00\t", "末尾\\", "'''\n\"\"\""} {
+		encoded, err := literal.Encode(s)
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+
+		got, err := literal.DecodeString(encoded)
+		if err != nil {
+			t.Fatalf("should success, but failed on %v: %v", encoded, err)
+		}
+		if got != s {
+			t.Errorf("want %q, but got %q from %v", s, got, encoded)
+		}
+
+		encodedBytes, err := literal.Encode([]byte(s))
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+
+		gotBytes, err := literal.DecodeBytes(encodedBytes)
+		if err != nil {
+			t.Fatalf("should success, but failed on %v: %v", encodedBytes, err)
+		}
+		if string(gotBytes) != s {
+			t.Errorf("want %q, but got %q from %v", s, gotBytes, encodedBytes)
+		}
+	}
+}
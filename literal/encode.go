@@ -0,0 +1,343 @@
+// Package literal converts between Go values and GoogleSQL literals.
+package literal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	ratType    = reflect.TypeOf(big.Rat{})
+	ratPtrType = reflect.TypeOf((*big.Rat)(nil))
+)
+
+// civil.Date is detected by its package path and name, so this package doesn't depend on cloud.google.com/go.
+const (
+	civilPkgPath  = "cloud.google.com/go/civil"
+	civilDateName = "Date"
+)
+
+// Encode encodes v to a GoogleSQL literal, or a constant expression if the type has no literal syntax.
+//
+// Supported values are:
+//   - nil as NULL, and typed nil pointers, slices and *big.Rat as CAST(NULL AS T)
+//   - bool, signed and unsigned integers as BOOL and INT64
+//   - float64 as FLOAT64, float32 as CAST(... AS FLOAT32), and NaN and infinities as CAST('nan' AS FLOAT64) etc.
+//   - string and []byte as STRING and BYTES literals, in raw or triple-quoted forms if they are more readable
+//   - time.Time as a TIMESTAMP literal in UTC, and cloud.google.com/go/civil.Date as a DATE literal
+//   - big.Rat and *big.Rat as a NUMERIC literal, they must be exact with 9 fractional digits
+//   - slices and arrays as ARRAY<T>[...], or [...] if the element type is an interface
+//   - structs as STRUCT<...>(...) of exported fields, named by `spanner` tags if present
+//   - pointers to the above
+func Encode(v any) (string, error) {
+	if v == nil {
+		return "NULL", nil
+	}
+	return encodeValue(reflect.ValueOf(v))
+}
+
+func encodeValue(rv reflect.Value) (string, error) {
+	t := rv.Type()
+
+	switch {
+	case t == timeType:
+		return "TIMESTAMP " + token.QuoteSQLString(rv.Interface().(time.Time).UTC().Format(time.RFC3339Nano)), nil
+	case isCivilDate(t):
+		return "DATE " + token.QuoteSQLString(fmt.Sprint(rv.Interface())), nil
+	case t == ratType:
+		r := rv.Interface().(big.Rat)
+		return encodeNumeric(&r)
+	case t == ratPtrType:
+		if rv.IsNil() {
+			return "CAST(NULL AS NUMERIC)", nil
+		}
+		return encodeNumeric(rv.Interface().(*big.Rat))
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		if rv.IsNil() {
+			return "CAST(NULL AS BYTES)", nil
+		}
+		return quoteBytes(rv.Bytes()), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return encodeValue(rv.Elem())
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return encodeTypedNull(t.Elem())
+		}
+		return encodeValue(rv.Elem())
+	case reflect.Bool:
+		return strings.ToUpper(strconv.FormatBool(rv.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return "", fmt.Errorf("%v overflows INT64", rv.Uint())
+		}
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float64:
+		return encodeFloat(rv.Float(), 64), nil
+	case reflect.Float32:
+		return fmt.Sprintf("CAST(%v AS FLOAT32)", encodeFloat(rv.Float(), 32)), nil
+	case reflect.String:
+		if !utf8.ValidString(rv.String()) {
+			return "", fmt.Errorf("string is not valid UTF-8: %q", rv.String())
+		}
+		return quoteString(rv.String()), nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return encodeTypedNull(t)
+		}
+		return encodeArray(rv)
+	case reflect.Array:
+		return encodeArray(rv)
+	case reflect.Struct:
+		return encodeStruct(rv)
+	default:
+		return "", fmt.Errorf("unsupported type: %v", t)
+	}
+}
+
+func encodeTypedNull(t reflect.Type) (string, error) {
+	name, err := typeName(t)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("CAST(NULL AS %v)", name), nil
+}
+
+// numericScale is 10^9, NUMERIC has 9 fractional digits.
+var numericScale = big.NewRat(1_000_000_000, 1)
+
+func encodeNumeric(r *big.Rat) (string, error) {
+	// FloatString rounds silently, so it must be exact at the scale.
+	if !new(big.Rat).Mul(r, numericScale).IsInt() {
+		return "", fmt.Errorf("numeric is not exact with 9 fractional digits: %v", r.RatString())
+	}
+
+	s := r.FloatString(9)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return "NUMERIC " + token.QuoteSQLString(s), nil
+}
+
+func encodeFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "CAST('nan' AS FLOAT64)"
+	case math.IsInf(f, 1):
+		return "CAST('inf' AS FLOAT64)"
+	case math.IsInf(f, -1):
+		return "CAST('-inf' AS FLOAT64)"
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+
+	// It must not be an integer literal.
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func encodeArray(rv reflect.Value) (string, error) {
+	elems := make([]string, 0, rv.Len())
+	for i := range rv.Len() {
+		elem, err := encodeValue(rv.Index(i))
+		if err != nil {
+			return "", fmt.Errorf("can't encode array element %v, err: %w", i, err)
+		}
+		elems = append(elems, elem)
+	}
+
+	list := "[" + strings.Join(elems, ", ") + "]"
+
+	// The element type is determined by values.
+	if rv.Type().Elem().Kind() == reflect.Interface {
+		if len(elems) == 0 {
+			return "", fmt.Errorf("can't determine the element type of empty %v", rv.Type())
+		}
+		return list, nil
+	}
+
+	name, err := typeName(rv.Type())
+	if err != nil {
+		return "", err
+	}
+	return name + list, nil
+}
+
+func encodeStruct(rv reflect.Value) (string, error) {
+	name, err := typeName(rv.Type())
+	if err != nil {
+		return "", err
+	}
+
+	var fields []string
+	for _, f := range structFields(rv.Type()) {
+		field, err := encodeValue(rv.FieldByIndex(f.Index))
+		if err != nil {
+			return "", fmt.Errorf("can't encode struct field %v, err: %w", f.Name, err)
+		}
+		fields = append(fields, field)
+	}
+	return name + "(" + strings.Join(fields, ", ") + ")", nil
+}
+
+// structFields returns exported fields of t, which are not skipped by `spanner:"-"`.
+func structFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous || f.Tag.Get("spanner") == "-" {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func fieldName(f reflect.StructField) string {
+	if name := f.Tag.Get("spanner"); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+func isCivilDate(t reflect.Type) bool {
+	return t.PkgPath() == civilPkgPath && t.Name() == civilDateName
+}
+
+// typeName returns the GoogleSQL type name of t.
+func typeName(t reflect.Type) (string, error) {
+	switch {
+	case t == timeType:
+		return "TIMESTAMP", nil
+	case isCivilDate(t):
+		return "DATE", nil
+	case t == ratType || t == ratPtrType:
+		return "NUMERIC", nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return "BYTES", nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	case reflect.Bool:
+		return "BOOL", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "INT64", nil
+	case reflect.Float64:
+		return "FLOAT64", nil
+	case reflect.Float32:
+		return "FLOAT32", nil
+	case reflect.String:
+		return "STRING", nil
+	case reflect.Slice, reflect.Array:
+		elem := t.Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		name, err := typeName(elem)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(name, "ARRAY<") {
+			return "", fmt.Errorf("array of array is not supported: %v", t)
+		}
+		return "ARRAY<" + name + ">", nil
+	case reflect.Struct:
+		var fields []string
+		for _, f := range structFields(t) {
+			name, err := typeName(f.Type)
+			if err != nil {
+				return "", fmt.Errorf("can't determine the type of struct field %v, err: %w", f.Name, err)
+			}
+			fields = append(fields, token.QuoteSQLIdent(fieldName(f))+" "+name)
+		}
+		return "STRUCT<" + strings.Join(fields, ", ") + ">", nil
+	default:
+		return "", fmt.Errorf("unsupported type: %v", t)
+	}
+}
+
+// quoteString quotes s in a readable form if possible, otherwise it quotes s with escapes.
+func quoteString(s string) string {
+	if q, ok := readableQuote(s, token.TokenString); ok {
+		return q
+	}
+	return token.QuoteSQLString(s)
+}
+
+// quoteBytes quotes b in a readable form if possible, otherwise it quotes b with escapes.
+func quoteBytes(b []byte) string {
+	if q, ok := readableQuote(string(b), token.TokenBytes); ok {
+		return q
+	}
+	return token.QuoteSQLBytes(b)
+}
+
+// readableQuote quotes s without escape sequences if s contains backslashes or newlines,
+// e.g. r"C:\path" and """multi-line""". kind is token.TokenString or token.TokenBytes.
+// It returns false if such forms can't represent s or they are not more readable than escapes.
+func readableQuote(s string, kind token.TokenKind) (string, bool) {
+	if !strings.ContainsAny(s, "\\\n") {
+		return "", false
+	}
+
+	prefix := ""
+	if kind == token.TokenBytes {
+		prefix = "b"
+	}
+
+	for _, r := range s {
+		// Bytes literals without escapes can only have ASCII characters.
+		if r != '\n' && (!unicode.IsPrint(r) || (kind == token.TokenBytes && r >= utf8.RuneSelf)) {
+			return "", false
+		}
+	}
+
+	multiline := strings.Contains(s, "\n")
+	raw := strings.Contains(s, "\\")
+	if raw {
+		prefix += "r"
+	}
+
+	for _, q := range []string{`"`, "'"} {
+		delim := q
+		if multiline {
+			delim = strings.Repeat(q, 3)
+		}
+
+		switch {
+		case !multiline && strings.Contains(s, q),
+			multiline && (strings.Contains(s, delim) || strings.HasSuffix(s, q)),
+			// A backslash in raw literals still escapes the following character.
+			raw && (strings.Contains(s, `\`+q) || strings.HasSuffix(s, `\`)):
+			continue
+		}
+
+		// Verify the result is decoded to s.
+		result := prefix + delim + s + delim
+		if tok, err := lexSingleLiteral(result, kind); err == nil && tok.AsString == s {
+			return result, true
+		}
+	}
+	return "", false
+}
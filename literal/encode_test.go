@@ -0,0 +1,93 @@
+package literal_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils/literal"
+)
+
+func TestEncode(t *testing.T) {
+	type inner struct {
+		Name string `spanner:"name"`
+		Skip string `spanner:"-"`
+		Tags []string
+	}
+
+	for _, tt := range []struct {
+		desc  string
+		input any
+		want  string
+	}{
+		{desc: "nil", input: nil, want: "NULL"},
+		{desc: "bool", input: true, want: "TRUE"},
+		{desc: "int", input: -1, want: "-1"},
+		{desc: "uint", input: uint32(1), want: "1"},
+		{desc: "float64", input: 1.0, want: "1.0"},
+		{desc: "float64 exponent", input: 1e100, want: "1e+100"},
+		{desc: "float64 NaN", input: math.NaN(), want: "CAST('nan' AS FLOAT64)"},
+		{desc: "float64 -Inf", input: math.Inf(-1), want: "CAST('-inf' AS FLOAT64)"},
+		{desc: "float32", input: float32(0.1), want: "CAST(0.1 AS FLOAT32)"},
+		{desc: "string", input: "it's", want: `"it's"`},
+		{desc: "string with control characters", input: "a\tb\x00", want: `"a\tb\x00"`},
+		{desc: "raw string", input: `C:\path "a"`, want: `r'C:\path "a"'`},
+		{desc: "triple-quoted string", input: "it's\nmulti-line", want: "\"\"\"it's\nmulti-line\"\"\""},
+		{desc: "raw triple-quoted string", input: "a\\n\n\"", want: "r'''a\\n\n\"'''"},
+		{desc: "escaped multi-line string", input: "a\n\t", want: `"a\n\t"`},
+		{desc: "raw string ending with backslash", input: `a\`, want: `"a\\"`},
+		{desc: "bytes", input: []byte("a\x00'"), want: `b"a\x00'"`},
+		{desc: "raw bytes", input: []byte(`\d+`), want: `br"\d+"`},
+		{desc: "timestamp", input: time.Date(2020, 1, 2, 3, 4, 5, 600, time.FixedZone("JST", 9*60*60)), want: `TIMESTAMP "2020-01-01T18:04:05.0000006Z"`},
+		{desc: "numeric", input: big.NewRat(3, 2), want: `NUMERIC "1.5"`},
+		{desc: "numeric integer", input: *big.NewRat(10, 1), want: `NUMERIC "10"`},
+		{desc: "numeric with 9 fractional digits", input: big.NewRat(-1, 1_000_000_000), want: `NUMERIC "-0.000000001"`},
+		{desc: "typed nil pointer", input: (*int64)(nil), want: "CAST(NULL AS INT64)"},
+		{desc: "nil numeric", input: (*big.Rat)(nil), want: "CAST(NULL AS NUMERIC)"},
+		{desc: "nil bytes", input: []byte(nil), want: "CAST(NULL AS BYTES)"},
+		{desc: "nil slice", input: []string(nil), want: "CAST(NULL AS ARRAY<STRING>)"},
+		{desc: "pointer", input: new(string), want: `""`},
+		{desc: "array", input: []int64{1, 2}, want: "ARRAY<INT64>[1, 2]"},
+		{desc: "empty array", input: []float64{}, want: "ARRAY<FLOAT64>[]"},
+		{desc: "array with NULL", input: []*bool{nil}, want: "ARRAY<BOOL>[CAST(NULL AS BOOL)]"},
+		{desc: "untyped array", input: []any{1, "a"}, want: `[1, "a"]`},
+		{
+			desc:  "struct",
+			input: inner{Name: "a", Skip: "b", Tags: []string{"c"}},
+			want:  `STRUCT<name STRING, Tags ARRAY<STRING>>("a", ARRAY<STRING>["c"])`,
+		},
+		{desc: "array of struct", input: []struct{ Order int }{{1}}, want: "ARRAY<STRUCT<`Order` INT64>>[STRUCT<`Order` INT64>(1)]"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := literal.Encode(tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		desc  string
+		input any
+	}{
+		{desc: "uint64 overflow", input: uint64(math.MaxUint64)},
+		{desc: "invalid UTF-8", input: "\xff"},
+		{desc: "array of array", input: [][]int64{{1}}},
+		{desc: "empty untyped array", input: []any{}},
+		{desc: "map", input: map[string]int{}},
+		{desc: "numeric with more than 9 fractional digits", input: big.NewRat(1, 3)},
+		{desc: "numeric with 10 fractional digits", input: *big.NewRat(1, 10_000_000_000)},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got, err := literal.Encode(tt.input); err == nil {
+				t.Errorf("should fail, but success: %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// Minify converts s to the smallest token-equivalent text without comments.
+// Tokens are joined without whitespaces unless the lexer would split them differently, e.g. `SELECT 1`, `a- -b` and `r 'x'` keep a whitespace.
+// Lexing the result gives the same token kinds and raw values as s.
+// filepath can be empty, it is only used in error message.
+func Minify(filepath, s string) (string, error) {
+	var tokens []token.Token
+	for tok, err := range NewLexerSeq(filepath, s) {
+		if err != nil {
+			return "", fmt.Errorf("error on Minify, err: %w", err)
+		}
+		if tok.Kind == token.TokenEOF {
+			break
+		}
+		tokens = append(tokens, tok)
+	}
+
+	// spaced[i] is true if a whitespace is needed before tokens[i].
+	spaced := make([]bool, len(tokens))
+
+	// Each token is joined to the output if the lexer still splits the same tokens in a window of the output.
+	var b strings.Builder
+	starts := make([]int, len(tokens))
+	for i, tok := range tokens {
+		if i > 0 {
+			// The lexer reads a token after "." as an identifier, so the window starts before dots.
+			from := i - 1
+			for from > 0 && (tokens[from-1].Kind == "." || tokens[from].Kind == ".") {
+				from--
+			}
+
+			window := b.String()[starts[from]:] + tok.Raw
+			if _, ok := firstMismatchToken(filepath, window, tokens[from:i+1]); !ok {
+				spaced[i] = true
+				b.WriteByte(' ')
+			}
+		}
+		starts[i] = b.Len()
+		b.WriteString(tok.Raw)
+	}
+
+	// Windows are usually enough, but the round-trip is verified and whitespaces are added until it passes.
+	for {
+		result := joinTokens(tokens, spaced)
+		i, ok := firstMismatchToken(filepath, result, tokens)
+		if ok {
+			return result, nil
+		}
+		if i == 0 || i >= len(tokens) || spaced[i] {
+			return "", fmt.Errorf("BUG: Minify can't preserve tokens at %v", i)
+		}
+		spaced[i] = true
+	}
+}
+
+func joinTokens(tokens []token.Token, spaced []bool) string {
+	var b strings.Builder
+	for i, tok := range tokens {
+		if spaced[i] {
+			b.WriteByte(' ')
+		}
+		b.WriteString(tok.Raw)
+	}
+	return b.String()
+}
+
+// firstMismatchToken lexes s and compares it with tokens.
+// It returns the index of the first mismatched token and false, or len(tokens) and true if all tokens match.
+func firstMismatchToken(filepath, s string, tokens []token.Token) (int, bool) {
+	i := 0
+	for tok, err := range NewLexerSeq(filepath, s) {
+		if err != nil || len(tok.Comments) > 0 {
+			return i, false
+		}
+		if tok.Kind == token.TokenEOF {
+			break
+		}
+		if i >= len(tokens) || tok.Kind != tokens[i].Kind || tok.Raw != tokens[i].Raw {
+			return i, false
+		}
+		i++
+	}
+	return i, i == len(tokens)
+}
@@ -0,0 +1,42 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestMinify(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  string
+	}{
+		{desc: "empty", input: "", want: ""},
+		{desc: "keywords and numbers", input: "SELECT 1 , a . b FROM t", want: "SELECT 1,a.b FROM t"},
+		{desc: "comments", input: "SELECT /* c */ 1 -- c\n; # c\nSELECT 2", want: "SELECT 1;SELECT 2"},
+		{desc: "minus", input: "SELECT a - -b, a - - 1, 1 - 2", want: "SELECT a- -b,a- -1,1-2"},
+		{desc: "string prefixes", input: "SELECT r 'x', r'x', b \"y\"", want: "SELECT r 'x',r'x',b \"y\""},
+		{desc: "literals are preserved", input: "SELECT '''a\n  b''' , \"c  d\" , `e f`", want: "SELECT'''a\n  b''',\"c  d\",`e f`"},
+		{desc: "hints and params", input: "@{ FORCE_INDEX = idx } SELECT @p , @@x FROM t @{ a = 1 }", want: "@{FORCE_INDEX=idx}SELECT@p,@@x FROM t@{a=1}"},
+		{desc: "numbers and dots", input: "SELECT 1 . 5, t . 1, 1 e3, .5", want: "SELECT 1 . 5,t.1,1 e3,.5"},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.Minify("", tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := gsqlutils.Minify("", "SELECT 'unclosed"); err == nil {
+			t.Error("should fail, but success")
+		}
+	})
+}
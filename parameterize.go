@@ -0,0 +1,247 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/samber/lo"
+
+	"github.com/apstndb/gsqlutils/internal"
+	"github.com/apstndb/gsqlutils/keywords"
+	"github.com/apstndb/gsqlutils/literal"
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+// ParameterValue is a typed value of a query parameter extracted by Parameterize.
+type ParameterValue struct {
+	// Type is the GoogleSQL type name of the value, e.g. INT64, STRING or DATE.
+	Type string
+
+	// Value is int64 for INT64, float64 for FLOAT64, string for STRING, []byte for BYTES and bool for BOOL.
+	// It is the content of the string literal for typed literals, e.g. "2020-01-01" for DATE '2020-01-01'.
+	Value any
+}
+
+// Literal returns the GoogleSQL literal of v.
+// Values of typed literals are encoded as typed literals of Type, e.g. DATE "2020-01-01", so they keep their types.
+// Other values are encoded by literal.Encode.
+func (v ParameterValue) Literal() (string, error) {
+	lit, err := literal.Encode(v.Value)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := v.Value.(string); ok && lo.Contains(typedLiteralTypes, v.Type) {
+		return v.Type + " " + lit, nil
+	}
+	return lit, nil
+}
+
+// typedLiteralTypes are type names of typed literals which can be parameters, e.g. DATE '2020-01-01'.
+var typedLiteralTypes = []string{"DATE", "TIMESTAMP", "NUMERIC", "JSON"}
+
+// parameterizeSkipDDLTokens are first tokens of DDL statements, which can't have query parameters.
+var parameterizeSkipDDLTokens = []string{"CREATE", "ALTER", "DROP", "RENAME", "GRANT", "REVOKE", "ANALYZE"}
+
+// Parameterize rewrites inline literals in a statement into named query parameters `@p1`, `@p2`, ...,
+// and returns the rewritten statement and the parameters keyed by their names without `@`.
+// INT64, FLOAT64, STRING, BYTES and BOOL literals, and typed literals like DATE '...', TIMESTAMP '...', NUMERIC '...' and JSON '...' are parameterized.
+// A unary minus is a part of the numeric literal.
+//
+// Literals which can't be parameters are left alone:
+// literals in hints, LIMIT and OFFSET counts, ordinals in GROUP BY and ORDER BY, type arguments like STRING(10) and ARRAY<...>,
+// operands of IS, string literals after INTERVAL and COLLATE, and all literals in DDL statements.
+// Names already used in the statement are not generated. Text other than parameterized literals, including comments, is preserved.
+// filepath can be empty, it is only used in error message.
+func Parameterize(filepath, s string) (string, map[string]ParameterValue, error) {
+	var tokens []token.Token
+	for tok, err := range NewLexerSeq(filepath, s) {
+		if err != nil {
+			return "", nil, fmt.Errorf("error on Parameterize, err: %w", err)
+		}
+		if tok.Kind == token.TokenEOF {
+			break
+		}
+		tokens = append(tokens, tok)
+	}
+
+	params := make(map[string]ParameterValue)
+	if isDDLTokens(tokens) {
+		return s, params, nil
+	}
+
+	used := make(map[string]bool)
+	for _, tok := range tokens {
+		if tok.Kind == token.TokenParam {
+			used[strings.ToLower(tok.AsString)] = true
+		}
+	}
+
+	n := 0
+	nextName := func() string {
+		for {
+			n++
+			if name := fmt.Sprintf("p%d", n); !used[name] {
+				return name
+			}
+		}
+	}
+
+	var result []token.Token
+	var hints tokenfilter.HintScanner
+	var compoundTypeLevel int
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		prev := nthToken(tokens[:i], -1)
+
+		// Malformed hints are left alone as best effort basis.
+		hintRole, _ := hints.Next(tok)
+		if (compoundTypeLevel > 0 || internal.OneOf(prev.Kind, "ARRAY", "STRUCT")) && tok.Kind == "<" {
+			compoundTypeLevel++
+		}
+
+		if hintRole != tokenfilter.HintRoleNone || compoundTypeLevel > 0 {
+			switch {
+			case compoundTypeLevel > 0 && tok.Kind == ">":
+				compoundTypeLevel--
+			case compoundTypeLevel > 0 && tok.Kind == ">>":
+				compoundTypeLevel = max(0, compoundTypeLevel-2)
+			}
+			result = append(result, tok)
+			continue
+		}
+
+		// unary minus is a part of the numeric literal
+		last, hasLast := lo.Last(result)
+		negative := hasLast && last.Kind == "-" && internal.OneOf(tok.Kind, token.TokenInt, token.TokenFloat) &&
+			!isOperandEnd(prevKind(result, 2))
+
+		value, width, ok := literalParameter(tokens, i, negative)
+		if !ok {
+			result = append(result, tok)
+			continue
+		}
+
+		replaced := token.Token{Kind: token.TokenParam, Pos: tok.Pos, End: tokens[i+width-1].End}
+		if negative {
+			replaced.Pos = last.Pos
+			result = result[:len(result)-1]
+		}
+
+		name := nextName()
+		params[name] = value
+		replaced.AsString = name
+		replaced.Raw = separateReplacement(s, replaced.Pos, replaced.End, "@"+name)
+
+		result = append(result, replaced)
+		for _, consumed := range tokens[i+1 : i+width] {
+			_, _ = hints.Next(consumed)
+		}
+		i += width - 1
+	}
+
+	rewritten, err := RebuildSource(s, tokenSliceSeq(result))
+	if err != nil {
+		return "", nil, fmt.Errorf("error on Parameterize, err: %w", err)
+	}
+	return rewritten, params, nil
+}
+
+// isDDLTokens is true if the first non-hint token is a first token of DDL statements.
+func isDDLTokens(tokens []token.Token) bool {
+	next, stop := iter.Pull2(tokenfilter.StripHints(tokenSliceSeq(tokens)))
+	defer stop()
+
+	tok, err, ok := next()
+	if err != nil || !ok {
+		return false
+	}
+
+	return keywords.MatchAny(tok, parameterizeSkipDDLTokens...)
+}
+
+// literalParameter returns the value of the literal at tokens[i] and the number of its tokens, if it can be a parameter.
+// If negative is true, the numeric literal is negated by the preceding unary minus.
+func literalParameter(tokens []token.Token, i int, negative bool) (ParameterValue, int, bool) {
+	tok := tokens[i]
+	prev := nthToken(tokens[:i], -1)
+	next := nthToken(tokens, i+1)
+
+	// -9223372036854775808 is valid but 9223372036854775808 is not, so the sign is parsed together.
+	sign := lo.Ternary(negative, "-", "")
+
+	switch tok.Kind {
+	case token.TokenIdent:
+		if next.Kind != token.TokenString {
+			return ParameterValue{}, 0, false
+		}
+		for _, typ := range typedLiteralTypes {
+			if tok.IsKeywordLike(typ) {
+				return ParameterValue{Type: typ, Value: next.AsString}, 2, true
+			}
+		}
+	case token.TokenString:
+		if !internal.OneOf(prev.Kind, "INTERVAL", "COLLATE") {
+			return ParameterValue{Type: "STRING", Value: tok.AsString}, 1, true
+		}
+	case token.TokenBytes:
+		return ParameterValue{Type: "BYTES", Value: []byte(tok.AsString)}, 1, true
+	case token.TokenInt:
+		if prev.Kind == "LIMIT" || prev.IsKeywordLike("OFFSET") || isOrdinal(tokens, i) || isTypeArgument(tokens, i) {
+			return ParameterValue{}, 0, false
+		}
+		if v, err := literal.DecodeInt64(sign + tok.Raw); err == nil {
+			return ParameterValue{Type: "INT64", Value: v}, 1, true
+		}
+	case token.TokenFloat:
+		if v, err := literal.DecodeFloat64(sign + tok.Raw); err == nil {
+			return ParameterValue{Type: "FLOAT64", Value: v}, 1, true
+		}
+	case "TRUE", "FALSE":
+		if prev.Kind != "IS" && !(prev.Kind == "NOT" && prevKind(tokens[:i], 2) == "IS") {
+			return ParameterValue{Type: "BOOL", Value: tok.Kind == "TRUE"}, 1, true
+		}
+	}
+	return ParameterValue{}, 0, false
+}
+
+// isOrdinal is true if the integer literal at tokens[i] is a whole item of GROUP BY or ORDER BY.
+func isOrdinal(tokens []token.Token, i int) bool {
+	if !internal.OneOf(prevKind(tokens[:i], 1), "BY", ",") {
+		return false
+	}
+
+	next := nthToken(tokens, i+1)
+	if !keywords.IsKeywordToken(next) && !internal.OneOf(next.Kind, ",", ")", ";", token.TokenBad) {
+		return false
+	}
+
+	depth := 0
+	for j := i - 1; j >= 0; j-- {
+		switch kind := tokens[j].Kind; {
+		case kind == ")":
+			depth++
+		case kind == "(":
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case depth > 0:
+			continue
+		case kind == "BY":
+			return internal.OneOf(prevKind(tokens[:j], 1), "GROUP", "ORDER")
+		case internal.OneOf(kind, "SELECT", "FROM", "WHERE", "HAVING", "WINDOW", "LIMIT", "ON", "USING", "SET", ";"):
+			return false
+		}
+	}
+	return false
+}
+
+// isTypeArgument is true if the integer literal at tokens[i] is a length of STRING(n) or BYTES(n).
+func isTypeArgument(tokens []token.Token, i int) bool {
+	typ := nthToken(tokens[:i], -2)
+	return prevKind(tokens[:i], 1) == "(" && nthToken(tokens, i+1).Kind == ")" &&
+		(typ.IsKeywordLike("STRING") || typ.IsKeywordLike("BYTES"))
+}
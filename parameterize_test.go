@@ -0,0 +1,95 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestParameterize(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		input      string
+		want       string
+		wantParams map[string]gsqlutils.ParameterValue
+	}{
+		{
+			desc:  "scalar literals",
+			input: "SELECT 1, -2, a - 3, 0x1F, 010, 1.5e3, 'x', b'y', TRUE FROM t",
+			want:  "SELECT @p1, @p2, a - @p3, @p4, @p5, @p6, @p7, @p8, @p9 FROM t",
+			wantParams: map[string]gsqlutils.ParameterValue{
+				"p1": {Type: "INT64", Value: int64(1)},
+				"p2": {Type: "INT64", Value: int64(-2)},
+				"p3": {Type: "INT64", Value: int64(3)},
+				"p4": {Type: "INT64", Value: int64(31)},
+				"p5": {Type: "INT64", Value: int64(10)},
+				"p6": {Type: "FLOAT64", Value: 1500.0},
+				"p7": {Type: "STRING", Value: "x"},
+				"p8": {Type: "BYTES", Value: []byte("y")},
+				"p9": {Type: "BOOL", Value: true},
+			},
+		},
+		{
+			desc:  "typed literals",
+			input: "SELECT DATE '2020-01-01', TIMESTAMP \"2020-01-01T00:00:00Z\", NUMERIC '1.5', JSON '{}'",
+			want:  "SELECT @p1, @p2, @p3, @p4",
+			wantParams: map[string]gsqlutils.ParameterValue{
+				"p1": {Type: "DATE", Value: "2020-01-01"},
+				"p2": {Type: "TIMESTAMP", Value: "2020-01-01T00:00:00Z"},
+				"p3": {Type: "NUMERIC", Value: "1.5"},
+				"p4": {Type: "JSON", Value: "{}"},
+			},
+		},
+		{
+			desc:  "existing parameters and comments",
+			input: "SELECT * FROM t WHERE a = @p1 AND b = 'x'/* c */AND c = -9223372036854775808",
+			want:  "SELECT * FROM t WHERE a = @p1 AND b = @p2/* c */AND c = @p3",
+			wantParams: map[string]gsqlutils.ParameterValue{
+				"p2": {Type: "STRING", Value: "x"},
+				"p3": {Type: "INT64", Value: int64(-9223372036854775808)},
+			},
+		},
+		{
+			desc:       "literals left alone",
+			input:      "@{OPTIMIZER_VERSION=7} SELECT CAST(a AS STRING(10)), ARRAY<STRING(10)>[], a IS NOT TRUE, INTERVAL '1-2' YEAR TO MONTH FROM t @{FORCE_INDEX=idx} GROUP BY 1, a ORDER BY 2 DESC LIMIT 10 OFFSET 5",
+			want:       "@{OPTIMIZER_VERSION=7} SELECT CAST(a AS STRING(10)), ARRAY<STRING(10)>[], a IS NOT TRUE, INTERVAL '1-2' YEAR TO MONTH FROM t @{FORCE_INDEX=idx} GROUP BY 1, a ORDER BY 2 DESC LIMIT 10 OFFSET 5",
+			wantParams: map[string]gsqlutils.ParameterValue{},
+		},
+		{
+			desc:  "expressions in GROUP BY",
+			input: "SELECT a + 1 FROM t GROUP BY a + 1",
+			want:  "SELECT a + @p1 FROM t GROUP BY a + @p2",
+			wantParams: map[string]gsqlutils.ParameterValue{
+				"p1": {Type: "INT64", Value: int64(1)},
+				"p2": {Type: "INT64", Value: int64(1)},
+			},
+		},
+		{
+			desc:       "DDL",
+			input:      "CREATE TABLE t (pk INT64 DEFAULT (1)) PRIMARY KEY (pk)",
+			want:       "CREATE TABLE t (pk INT64 DEFAULT (1)) PRIMARY KEY (pk)",
+			wantParams: map[string]gsqlutils.ParameterValue{},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, gotParams, err := gsqlutils.Parameterize("", tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantParams, gotParams); diff != "" {
+				t.Errorf("difference in params: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, _, err := gsqlutils.Parameterize("", "SELECT 'unclosed"); err == nil {
+			t.Error("should fail, but success")
+		}
+	})
+}
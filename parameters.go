@@ -0,0 +1,47 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// QueryParameter is a query parameter referenced in a statement.
+type QueryParameter struct {
+	// Name is the name without `@` in the first reference.
+	// Names are case-insensitive, so references in different cases are the same parameter.
+	Name string
+
+	// Refs are the ranges of all references in order, including `@`.
+	Refs []Range
+}
+
+// Parameters returns query parameters referenced in s in order of their first references.
+// Hints and system variables like `@@statement_timeout` are not query parameters.
+// filepath can be empty, it is only used in error message.
+func Parameters(filepath, s string) ([]QueryParameter, error) {
+	idx := NewPositionIndex(s)
+
+	var result []QueryParameter
+	indices := make(map[string]int)
+	for tok, err := range newStripHintsSeq(filepath, s) {
+		if err != nil {
+			return nil, fmt.Errorf("error on Parameters, err: %w", err)
+		}
+
+		if tok.Kind != token.TokenParam {
+			continue
+		}
+
+		key := strings.ToUpper(tok.AsString)
+		i, ok := indices[key]
+		if !ok {
+			i = len(result)
+			indices[key] = i
+			result = append(result, QueryParameter{Name: tok.AsString})
+		}
+		result[i].Refs = append(result[i].Refs, idx.Range(tok.Pos, tok.End))
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestParameters(t *testing.T) {
+	type param struct {
+		Name string
+		Refs []string
+	}
+
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []param
+	}{
+		{desc: "no parameters", input: "SELECT 1", want: nil},
+		{
+			desc:  "references",
+			input: "SELECT @a, @b FROM t\nWHERE x = @A AND y = @b",
+			want: []param{
+				{Name: "a", Refs: []string{"1:8-1:10", "2:11-2:13"}},
+				{Name: "b", Refs: []string{"1:12-1:14", "2:22-2:24"}},
+			},
+		},
+		{
+			desc:  "hints and system variables",
+			input: "@{OPTIMIZER_VERSION=7} SELECT @@statement_timeout, @p FROM t @{FORCE_INDEX=idx}",
+			want:  []param{{Name: "p", Refs: []string{"1:52-1:54"}}},
+		},
+		{
+			desc:  "comments and literals",
+			input: "SELECT '@a', /* @b */ @c -- @d",
+			want:  []param{{Name: "c", Refs: []string{"1:23-1:25"}}},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			params, err := gsqlutils.Parameters("", tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+
+			var got []param
+			for _, p := range params {
+				var refs []string
+				for _, r := range p.Refs {
+					refs = append(refs, r.Start.String()+"-"+r.End.String())
+				}
+				got = append(got, param{Name: p.Name, Refs: refs})
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := gsqlutils.Parameters("", "SELECT @a, 'unclosed"); err == nil {
+			t.Error("should fail, but success")
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// Location is a resolved position in an input.
+// Line and columns are 0-origin like token.Position.
+type Location struct {
+	Pos token.Pos
+
+	Line int
+
+	// Column is the number of bytes from the head of the line.
+	Column int
+
+	// RuneColumn is the number of runes from the head of the line.
+	RuneColumn int
+
+	// UTF16Column is the number of UTF-16 code units from the head of the line, it is used by LSP.
+	UTF16Column int
+}
+
+// String returns 1-origin "line:column" with the rune column.
+func (l Location) String() string {
+	return fmt.Sprintf("%d:%d", l.Line+1, l.RuneColumn+1)
+}
+
+// Range is a pair of resolved positions.
+type Range struct {
+	Start, End Location
+}
+
+// PositionIndex maps token.Pos in an input to Location in O(log n).
+type PositionIndex struct {
+	// lines are the heads of lines.
+	lines []token.Pos
+
+	// multibytes are non-ASCII runes in the input.
+	multibytes []multibyteRune
+}
+
+type multibyteRune struct {
+	pos token.Pos
+
+	// extraBytes is the cumulative number of bytes exceeding one byte per rune until this rune.
+	extraBytes int
+
+	// extraUTF16 is the cumulative number of surrogate pairs until this rune.
+	extraUTF16 int
+}
+
+// NewPositionIndex builds a PositionIndex over s.
+func NewPositionIndex(s string) *PositionIndex {
+	idx := &PositionIndex{lines: []token.Pos{0}}
+
+	var extraBytes, extraUTF16 int
+	for i, size := 0, 0; i < len(s); i += size {
+		var r rune
+		r, size = utf8.DecodeRuneInString(s[i:])
+		if r == '\n' {
+			idx.lines = append(idx.lines, token.Pos(i+1))
+		}
+
+		if size == 1 {
+			continue
+		}
+
+		extraBytes += size - 1
+		if r >= 0x10000 {
+			extraUTF16++
+		}
+		idx.multibytes = append(idx.multibytes, multibyteRune{pos: token.Pos(i), extraBytes: extraBytes, extraUTF16: extraUTF16})
+	}
+	return idx
+}
+
+// lazyPositionIndex returns a function which builds a PositionIndex over s on the first call.
+func lazyPositionIndex(s string) func() *PositionIndex {
+	var idx *PositionIndex
+	return func() *PositionIndex {
+		if idx == nil {
+			idx = NewPositionIndex(s)
+		}
+		return idx
+	}
+}
+
+// Location resolves pos. It returns Location with -1 line and columns if pos is invalid.
+func (idx *PositionIndex) Location(pos token.Pos) Location {
+	if pos.Invalid() {
+		return Location{Pos: pos, Line: -1, Column: -1, RuneColumn: -1, UTF16Column: -1}
+	}
+
+	line, found := slices.BinarySearch(idx.lines, pos)
+	if !found {
+		line--
+	}
+	lineHead := idx.lines[line]
+
+	headBytes, headUTF16 := idx.extraBefore(lineHead)
+	posBytes, posUTF16 := idx.extraBefore(pos)
+
+	column := int(pos - lineHead)
+	runeColumn := column - (posBytes - headBytes)
+	return Location{
+		Pos:         pos,
+		Line:        line,
+		Column:      column,
+		RuneColumn:  runeColumn,
+		UTF16Column: runeColumn + (posUTF16 - headUTF16),
+	}
+}
+
+// Range resolves pos and end.
+func (idx *PositionIndex) Range(pos, end token.Pos) Range {
+	return Range{Start: idx.Location(pos), End: idx.Location(end)}
+}
+
+// extraBefore returns cumulative extraBytes and extraUTF16 of runes before pos.
+func (idx *PositionIndex) extraBefore(pos token.Pos) (int, int) {
+	i, _ := slices.BinarySearchFunc(idx.multibytes, pos, func(r multibyteRune, pos token.Pos) int {
+		return int(r.pos - pos)
+	})
+	if i == 0 {
+		return 0, 0
+	}
+	r := idx.multibytes[i-1]
+	return r.extraBytes, r.extraUTF16
+}
+
+// Range resolves the range of stmt.
+func (stmt RawStatement) Range(idx *PositionIndex) Range {
+	return idx.Range(stmt.Pos, stmt.End)
+}
+
+// SeparateInputWithIndex is same as SeparateInput, but it also returns a PositionIndex of s to resolve positions in the result.
+func SeparateInputWithIndex(filepath, s string, opts ...SeparateOption) ([]RawStatement, *PositionIndex, error) {
+	stmts, err := SeparateInput(filepath, s, opts...)
+	return stmts, NewPositionIndex(s), err
+}
+
+// advanceLocation returns the location after s which starts at loc.
+func advanceLocation(loc Location, s string) Location {
+	loc.Pos += token.Pos(len(s))
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		loc.Line += strings.Count(s, "\n")
+		loc.Column, loc.RuneColumn, loc.UTF16Column = 0, 0, 0
+		s = s[i+1:]
+	}
+
+	loc.Column += len(s)
+	for _, r := range s {
+		loc.RuneColumn++
+		loc.UTF16Column += utf16Len(r)
+	}
+	return loc
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// shiftLocation shifts loc which is resolved in a buffer starting at base.
+func shiftLocation(loc Location, base Location) Location {
+	if loc.Pos.Invalid() {
+		return loc
+	}
+
+	loc.Pos += base.Pos
+	if loc.Line == 0 {
+		loc.Column += base.Column
+		loc.RuneColumn += base.RuneColumn
+		loc.UTF16Column += base.UTF16Column
+	}
+	loc.Line += base.Line
+	return loc
+}
@@ -0,0 +1,36 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPositionIndex(t *testing.T) {
+	const input = "SELECT 1;\nSELECT 'ｓｅｌｅｃｔ' AS `列`, '🍣' AS x;\n\nSELECT 3"
+
+	for _, tt := range []struct {
+		desc string
+		pos  token.Pos
+		want gsqlutils.Location
+	}{
+		{desc: "head", pos: 0, want: gsqlutils.Location{Pos: 0}},
+		{desc: "first line", pos: 7, want: gsqlutils.Location{Pos: 7, Column: 7, RuneColumn: 7, UTF16Column: 7}},
+		{desc: "newline", pos: 9, want: gsqlutils.Location{Pos: 9, Column: 9, RuneColumn: 9, UTF16Column: 9}},
+		{desc: "head of second line", pos: 10, want: gsqlutils.Location{Pos: 10, Line: 1}},
+		{desc: "after full-width characters", pos: 36, want: gsqlutils.Location{Pos: 36, Line: 1, Column: 26, RuneColumn: 14, UTF16Column: 14}},
+		{desc: "after surrogate pair", pos: 53, want: gsqlutils.Location{Pos: 53, Line: 1, Column: 43, RuneColumn: 26, UTF16Column: 27}},
+		{desc: "empty line", pos: 61, want: gsqlutils.Location{Pos: 61, Line: 2}},
+		{desc: "last line", pos: 70, want: gsqlutils.Location{Pos: 70, Line: 3, Column: 8, RuneColumn: 8, UTF16Column: 8}},
+		{desc: "invalid", pos: token.InvalidPos, want: gsqlutils.Location{Pos: token.InvalidPos, Line: -1, Column: -1, RuneColumn: -1, UTF16Column: -1}},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := gsqlutils.NewPositionIndex(input).Location(tt.pos)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in Location: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package gsqlutils
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"strings"
+	"unicode"
+
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/samber/lo"
+)
+
+// readChunkSize is the size of a chunk which SeparateReaderSeq reads at once.
+const readChunkSize = 64 * 1024
+
+// SeparateReaderSeq is a streaming version of SeparateInput.
+// It reads r in chunks and yields each statement as soon as its terminator is read,
+// so memory use is bounded by the largest statement rather than the whole input.
+// A statement continued across chunks is not lexed again from its head, lexing resumes from its last token.
+// Pos and End of yielded statements are byte offsets from the beginning of r.
+// It stops after the first error.
+// filepath can be empty, it is only used in error message.
+func SeparateReaderSeq(filepath string, r io.Reader, opts ...SeparateOption) iter.Seq2[RawStatement, error] {
+	return func(yield func(RawStatement, error) bool) {
+		sp := newSeparator(filepath, opts...)
+
+		var buf strings.Builder
+
+		// base is the location of buf in the whole input.
+		var base Location
+
+		// afterTerminator is true if buf starts after a terminator and before the head of the next statement.
+		// In that case, leading whitespaces are not a part of the next statement.
+		var afterTerminator bool
+
+		// rp is the resume point of the pending statement at the head of buf.
+		var rp resumePoint
+
+		chunk := make([]byte, readChunkSize)
+		for eof := false; !eof; {
+			n, err := r.Read(chunk)
+			buf.Write(chunk[:n])
+			switch {
+			case errors.Is(err, io.EOF):
+				eof = true
+			case err != nil:
+				_ = yield(RawStatement{}, err)
+				return
+			case n == 0:
+				continue
+			}
+
+			s := buf.String()
+			if afterTerminator {
+				trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+				base = advanceLocation(base, s[:len(s)-len(trimmed)])
+				s = trimmed
+				afterTerminator = s == ""
+
+				buf.Reset()
+				buf.WriteString(s)
+			}
+
+			result, err := sp.separateFrom(s, rp, eof)
+			stmts := result.statements
+
+			// Unless it is the end of input, an error caused by the end of the buffer may be resolved by the next chunk.
+			needMore := !eof && err != nil && isTruncationError(err, len(s))
+			if err != nil && !needMore {
+				for _, stmt := range stmts {
+					if !yield(shiftRawStatement(stmt, base.Pos), nil) {
+						return
+					}
+				}
+				_ = yield(RawStatement{}, shiftError(err, base))
+				return
+			}
+
+			for _, stmt := range stmts {
+				// An unterminated statement can be continued in the next chunk.
+				if !eof && stmt.Terminator == "" {
+					break
+				}
+
+				if !yield(shiftRawStatement(stmt, base.Pos), nil) {
+					return
+				}
+			}
+
+			if result.consumed > 0 {
+				afterTerminator = true
+				sp.delimiter = result.delimiter
+			}
+
+			// Cut buf before the pending statement, it is resumed by the next chunk.
+			cut := result.consumed
+			rp = resumePoint{}
+			if last, ok := lo.Last(stmts); ok && last.Terminator == "" {
+				cut, rp = last.Pos, result.resume.rebase(s, last.Pos)
+				afterTerminator = false
+			}
+
+			if cut > 0 {
+				base = advanceLocation(base, s[:cut])
+				buf.Reset()
+				buf.WriteString(s[cut:])
+			}
+		}
+	}
+}
+
+// isTruncationError returns true if err can be caused by the end of a buffer of length bufLen.
+func isTruncationError(err error, bufLen int) bool {
+	if _, ok := lo.ErrorsAs[*ErrLexerStatus](err); ok {
+		return true
+	}
+
+	if err, ok := lo.ErrorsAs[*memefish.Error](err); ok {
+		return int(err.Position.End) >= bufLen
+	}
+	return false
+}
+
+func shiftRawStatement(stmt RawStatement, offset token.Pos) RawStatement {
+	stmt.Pos += offset
+	stmt.End += offset
+	return stmt
+}
+
+// shiftError shifts positions of err, it is needed when the buffer doesn't start at the beginning of input.
+// base is the location of the head of the buffer.
+func shiftError(err error, base Location) error {
+	if status, ok := lo.ErrorsAs[*ErrLexerStatus](err); ok {
+		shifted := *status
+		shifted.Location = shiftLocation(status.Location, base)
+		shifted.Open = lo.Map(status.Open, func(c OpenConstruct, _ int) OpenConstruct {
+			c.Location = shiftLocation(c.Location, base)
+			return c
+		})
+		return &shifted
+	}
+
+	if lerr, ok := lo.ErrorsAs[*LexerError](err); ok {
+		return &LexerError{Kind: lerr.Kind, Err: shiftMemefishError(lerr.Err, base)}
+	}
+
+	if merr, ok := lo.ErrorsAs[*memefish.Error](err); ok {
+		return shiftMemefishError(merr, base)
+	}
+	return err
+}
+
+func shiftMemefishError(merr *memefish.Error, base Location) *memefish.Error {
+	if merr.Position == nil {
+		return merr
+	}
+
+	position := *merr.Position
+	position.Pos += base.Pos
+	position.End += base.Pos
+	if position.Line == 0 {
+		position.Column += base.Column
+	}
+	if position.EndLine == 0 {
+		position.EndColumn += base.Column
+	}
+	position.Line += base.Line
+	position.EndLine += base.Line
+
+	return &memefish.Error{Message: merr.Message, Position: &position}
+}
@@ -0,0 +1,66 @@
+package gsqlutils_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSeparateReaderSeq(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		input   string
+		wantErr bool
+	}{
+		{desc: "single statement", input: `SELECT 1`},
+		{desc: "multiple statements", input: "SELECT 1;\nSELECT 2;\n-- comment\nSELECT 3"},
+		{desc: "terminated statements", input: "CREATE TABLE t (pk INT64) PRIMARY KEY (pk);\nINSERT INTO t (pk) VALUES (1);\n"},
+		{desc: "semicolon in literals and comments", input: "SELECT ';';\nSELECT \"\"\"\n;\n\"\"\"; /* ; */ SELECT 3 -- ;\n;"},
+		{desc: "non-ASCII characters", input: "SELECT 'ｓｅｌｅｃｔ';\nSELECT `列`;"},
+		{desc: "large statement in multiple chunks", input: "SELECT 1;\nSELECT " + strings.Repeat("'a;', ", 20000) + "1;\nSELECT 3"},
+		{desc: "unclosed comment", input: "SELECT 1; SELECT /* 2", wantErr: true},
+		{desc: "unclosed comment after non-ASCII characters", input: "SELECT 'ｓｅｌｅｃｔ';\nSELECT 'ｓｅｌｅｃｔ', /* 2", wantErr: true},
+		{desc: "unclosed triple-quoted string", input: "SELECT 1;\nSELECT '''2;\n", wantErr: true},
+		{desc: "unclosed string at the head of statement", input: "SELECT 1;\n  'abc", wantErr: true},
+		{desc: "invalid number", input: "SELECT 1;\nSELECT 2a;\nSELECT 3;", wantErr: true},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			want, wantErr := gsqlutils.SeparateInputPreserveCommentsWithStatus("", tt.input)
+			if (wantErr != nil) != tt.wantErr {
+				t.Fatalf("unexpected error of SeparateInputPreserveCommentsWithStatus: %v", wantErr)
+			}
+
+			var got []gsqlutils.RawStatement
+			var gotErr error
+			for stmt, err := range gsqlutils.SeparateReaderSeq("", iotest.OneByteReader(strings.NewReader(tt.input))) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				got = append(got, stmt)
+			}
+
+			if tt.wantErr && gotErr == nil {
+				t.Error("should fail, but success")
+			}
+			if !tt.wantErr && gotErr != nil {
+				t.Errorf("should success, but failed: %v", gotErr)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+
+			// ErrLexerStatus must have the same location as non-streaming one.
+			var wantStatus *gsqlutils.ErrLexerStatus
+			if errors.As(wantErr, &wantStatus) {
+				if diff := cmp.Diff(wantStatus, gotErr); diff != "" {
+					t.Errorf("difference in err: (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
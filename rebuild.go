@@ -0,0 +1,72 @@
+package gsqlutils
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+// RebuildSource rebuilds s with Raw of tokens in seq, which is lexed from s and possibly rewritten by filters.
+// Comments are rebuilt with Raw of Comments of tokens, and other text between tokens, including whitespaces
+// and comments removed from Comments, is copied from s byte-for-byte.
+// Tokens and comments in seq must be in order of positions, and tokens not in seq are dropped.
+func RebuildSource(s string, seq iter.Seq2[token.Token, error]) (string, error) {
+	var b strings.Builder
+	var prevEnd token.Pos
+	for tok, err := range seq {
+		if err != nil {
+			return "", err
+		}
+
+		if tok.Pos < prevEnd || int(tok.End) > len(s) {
+			return "", fmt.Errorf("token %q at %v is out of order", tok.Raw, tok.Pos)
+		}
+
+		for _, c := range tok.Comments {
+			if c.Pos < prevEnd || c.End > tok.Pos {
+				return "", fmt.Errorf("comment %q at %v is out of order", c.Raw, c.Pos)
+			}
+
+			b.WriteString(s[prevEnd:c.Pos])
+			b.WriteString(c.Raw)
+			prevEnd = c.End
+		}
+
+		b.WriteString(s[prevEnd:tok.Pos])
+		b.WriteString(tok.Raw)
+		prevEnd = tok.End
+
+		if tok.Kind == token.TokenEOF {
+			break
+		}
+	}
+	b.WriteString(s[prevEnd:])
+	return b.String(), nil
+}
+
+// NormalizeKeywordCase rewrites reserved keywords, and unquoted identifiers which match one of keywordLikes case-insensitively, to keywordCase.
+// Everything else, including quoted identifiers, literals, comments, hints and whitespaces, is preserved byte-for-byte.
+// filepath can be empty, it is only used in error message.
+func NormalizeKeywordCase(filepath, s string, keywordCase tokenfilter.KeywordCase, keywordLikes ...string) (string, error) {
+	result, err := RebuildSource(s, tokenfilter.NormalizeKeywordCase(NewLexerSeq(filepath, s), keywordCase, keywordLikes...))
+	if err != nil {
+		return "", fmt.Errorf("error on NormalizeKeywordCase, err: %w", err)
+	}
+	return result, nil
+}
+
+// Redact replaces string, bytes and numeric literals in s with placeholders for logging, keeping the shape of statements.
+// Everything else, including identifiers, keywords, comments, hints and whitespaces, is preserved byte-for-byte, and line numbers are not shifted.
+// Comments can contain sensitive values, use tokenfilter.WithRedactComments to blank them.
+// filepath can be empty, it is only used in error message.
+func Redact(filepath, s string, opts ...tokenfilter.RedactOption) (string, error) {
+	result, err := RebuildSource(s, tokenfilter.Redact(NewLexerSeq(filepath, s), opts...))
+	if err != nil {
+		return "", fmt.Errorf("error on Redact, err: %w", err)
+	}
+	return result, nil
+}
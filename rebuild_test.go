@@ -0,0 +1,123 @@
+package gsqlutils_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+func TestNormalizeKeywordCase(t *testing.T) {
+	for _, tt := range []struct {
+		desc         string
+		input        string
+		keywordCase  tokenfilter.KeywordCase
+		keywordLikes []string
+		want         string
+	}{
+		{
+			desc:        "upper",
+			input:       "select  `select`, 'select', select_1\n-- select\nfrom t where x is not null",
+			keywordCase: tokenfilter.KeywordCaseUpper,
+			want:        "SELECT  `select`, 'select', select_1\n-- select\nFROM t WHERE x IS NOT NULL",
+		},
+		{
+			desc:        "lower",
+			input:       "SELECT /* SELECT */ ARRAY<STRUCT<x INT64>>[] FROM T",
+			keywordCase: tokenfilter.KeywordCaseLower,
+			want:        "select /* SELECT */ array<struct<x INT64>>[] from T",
+		},
+		{
+			desc:        "hints are preserved",
+			input:       "@{force_join_order=true} select * from t@{force_index=_base_table} join@{join_method=hash_join} u on true",
+			keywordCase: tokenfilter.KeywordCaseUpper,
+			want:        "@{force_join_order=true} SELECT * FROM t@{force_index=_base_table} JOIN@{join_method=hash_join} u ON TRUE",
+		},
+		{
+			desc:         "keyword-like identifiers",
+			input:        "insert into t (`insert`, update) values (1, 2); delete from t where true",
+			keywordCase:  tokenfilter.KeywordCaseUpper,
+			keywordLikes: []string{"INSERT", "DELETE", "VALUES"},
+			want:         "INSERT INTO t (`insert`, update) VALUES (1, 2); DELETE FROM t WHERE TRUE",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.NormalizeKeywordCase("", tt.input, tt.keywordCase, tt.keywordLikes...)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := gsqlutils.NormalizeKeywordCase("", "select 'unclosed", tokenfilter.KeywordCaseUpper); err == nil {
+			t.Error("should fail, but success")
+		}
+	})
+}
+
+func TestRedact(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []tokenfilter.RedactOption
+		want  string
+	}{
+		{
+			desc:  "typed style",
+			input: "SELECT 'secret', b\"\\x00\", -42, 1.5, @p, DATE '2020-01-01' FROM t -- 'comment'\nWHERE name = r'x'",
+			want:  "SELECT '***', b'***', -0, 0.0, @p, DATE '***' FROM t -- 'comment'\nWHERE name = '***'",
+		},
+		{
+			desc:  "question style with parameters",
+			input: "SELECT * FROM t WHERE a = @a AND b IN (1, 2)",
+			opts:  []tokenfilter.RedactOption{tokenfilter.WithRedactStyle(tokenfilter.RedactStyleQuestion), tokenfilter.WithRedactParameterNames()},
+			want:  "SELECT * FROM t WHERE a = ? AND b IN (?, ?)",
+		},
+		{
+			desc:  "comments",
+			input: "/* token: abc\n */ SELECT @{OPTIMIZER_VERSION=7 /* x */} 1 -- password\n# email\nFROM t --",
+			opts:  []tokenfilter.RedactOption{tokenfilter.WithRedactComments()},
+			want:  "/*\n*/ SELECT @{OPTIMIZER_VERSION=7 /**/} 0 --\n#\nFROM t --",
+		},
+		{
+			desc:  "custom style",
+			input: "SELECT 'a', 1",
+			opts: []tokenfilter.RedactOption{tokenfilter.WithRedactStyle(func(tok token.Token) string {
+				return fmt.Sprintf("<%d bytes>", len(tok.Raw))
+			})},
+			want: "SELECT <3 bytes>, <1 bytes>",
+		},
+		{
+			desc:  "hints are preserved",
+			input: "@{OPTIMIZER_VERSION=7} SELECT 1 FROM t@{FORCE_INDEX=idx}",
+			want:  "@{OPTIMIZER_VERSION=7} SELECT 0 FROM t@{FORCE_INDEX=idx}",
+		},
+		{
+			desc:  "multi-line strings keep line numbers",
+			input: "SELECT '''line1\nline2\nline3''' AS s,\n  x\nFROM t",
+			want:  "SELECT '***'\n\n AS s,\n  x\nFROM t",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.Redact("", tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+			if strings.Count(got, "\n") != strings.Count(tt.input, "\n") {
+				t.Errorf("line numbers are shifted: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package gsqlutils
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/cloudspannerecosystem/memefish"
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/samber/lo"
+)
+
+// Terminator is a statement terminator recognized by SeparateInput.
+type Terminator struct {
+	// Text is the terminator string, e.g. `;` or `\G`.
+	// It is recognized only at the head of a token or a comment.
+	Text string
+
+	// EndOfLine is true if Text is a terminator only at the end of a line,
+	// it means only whitespaces can follow it in the same line.
+	EndOfLine bool
+}
+
+const defaultDelimiter = ";"
+
+// SeparateOption is an option of SeparateInput, SeparateReaderSeq and NewInputBuffer.
+type SeparateOption func(*separator)
+
+// WithTerminators registers terminators in addition to `;`.
+// If some terminators match at the same position, the longest one is used.
+func WithTerminators(terminators ...Terminator) SeparateOption {
+	return func(sp *separator) {
+		sp.terminators = append(sp.terminators, terminators...)
+	}
+}
+
+// WithDelimiterCommand enables MySQL style `DELIMITER` command.
+// `DELIMITER <delimiter>` at the head of a statement replaces `;` with the rest of the line until the next command.
+// The command itself is not a part of the result.
+func WithDelimiterCommand() SeparateOption {
+	return func(sp *separator) {
+		sp.delimiterCommand = true
+	}
+}
+
+// SeparateInput separates an input string to statements at terminators without parsing.
+// It preserves all comments, and Terminator of each statement reports which terminator ended it.
+// Terminator of the last statement is empty if it is not terminated.
+// This function won't panic but return error if lexer become error state.
+// filepath can be empty, it is only used in error message.
+func SeparateInput(filepath, s string, opts ...SeparateOption) ([]RawStatement, error) {
+	result, err := newSeparator(filepath, opts...).separate(s, true)
+	return result.statements, err
+}
+
+// TokenizedStatement is a RawStatement with its tokens.
+type TokenizedStatement struct {
+	RawStatement
+
+	// Tokens are tokens of the statement. They don't contain the terminator and EOF.
+	Tokens []token.Token
+}
+
+// SeparateInputTokens separates an input string to statements like SeparateInput,
+// and also returns tokens of each statement without lexing again.
+// filepath can be empty, it is only used in error message.
+func SeparateInputTokens(filepath, s string, opts ...SeparateOption) ([]TokenizedStatement, error) {
+	sp := newSeparator(filepath, opts...)
+	sp.keepTokens = true
+
+	result, err := sp.separate(s, true)
+	return lo.ZipBy2(result.statements, result.tokens, func(stmt RawStatement, tokens []token.Token) TokenizedStatement {
+		return TokenizedStatement{RawStatement: stmt, Tokens: tokens}
+	}), err
+}
+
+// TokenSeq returns tokens of the statement as iter.Seq2 like LexerSeq.
+// It ends with EOF token at the end of the statement.
+func (stmt TokenizedStatement) TokenSeq() iter.Seq2[token.Token, error] {
+	return func(yield func(token.Token, error) bool) {
+		for _, tok := range stmt.Tokens {
+			if !yield(tok, nil) {
+				return
+			}
+		}
+
+		end := stmt.Pos + token.Pos(len(stmt.Statement))
+		_ = yield(token.Token{Kind: token.TokenEOF, Pos: end, End: end}, nil)
+	}
+}
+
+type separator struct {
+	filepath string
+
+	// delimiter is the primary terminator, it can be changed by DELIMITER command.
+	delimiter string
+
+	terminators      []Terminator
+	delimiterCommand bool
+
+	// keepTokens is true if separateResult.tokens is needed.
+	keepTokens bool
+}
+
+func newSeparator(filepath string, opts ...SeparateOption) *separator {
+	sp := &separator{filepath: filepath, delimiter: defaultDelimiter}
+	for _, opt := range opts {
+		opt(sp)
+	}
+	return sp
+}
+
+type separateResult struct {
+	statements []RawStatement
+
+	// tokens are tokens of each statement, it is only available if separator.keepTokens is true.
+	tokens [][]token.Token
+
+	// consumed is the end of the last terminated statement or DELIMITER command.
+	consumed token.Pos
+
+	// delimiter is the delimiter at consumed.
+	delimiter string
+
+	// resume is the point to resume separating the last statement if it is not terminated.
+	resume resumePoint
+}
+
+// resumePoint is a point to resume separating a statement which is not terminated, when the input is continued.
+// The statement is lexed again only from the head of its last token, because the last token can be continued.
+type resumePoint struct {
+	// pos is the head of the statement.
+	pos token.Pos
+
+	// offset is the position to restart the lexer.
+	offset token.Pos
+
+	// loc is the location of offset.
+	loc Location
+
+	// tokens are tokens of the statement before offset.
+	tokens []token.Token
+}
+
+// rebase returns rp relative to s[cut:], it is needed when the buffer s is cut before rp.pos.
+func (rp resumePoint) rebase(s string, cut token.Pos) resumePoint {
+	if cut == 0 {
+		return rp
+	}
+
+	rp.pos -= cut
+	rp.offset -= cut
+	rp.loc = advanceLocation(Location{}, s[cut:cut+rp.offset])
+	rp.tokens = lo.Map(rp.tokens, func(tok token.Token, _ int) token.Token {
+		return shiftToken(tok, -cut)
+	})
+	return rp
+}
+
+// activeTerminators returns the terminators in the longest first order.
+func activeTerminators(delimiter string, terminators []Terminator) []Terminator {
+	result := append([]Terminator{{Text: delimiter}}, terminators...)
+	slices.SortStableFunc(result, func(a, b Terminator) int {
+		return cmp.Compare(len(b.Text), len(a.Text))
+	})
+	return result
+}
+
+// separate separates s to statements.
+// If final is false, s can be continued by the following input,
+// so a terminator which can be changed by the following input is not recognized.
+func (sp *separator) separate(s string, final bool) (separateResult, error) {
+	return sp.separateFrom(s, resumePoint{}, final)
+}
+
+// separateFrom is same as separate, but it resumes separating the first statement of s at rp.
+// rp is the resume point of the previous result, rebased to be relative to s.
+// s before rp.offset is not lexed again, so the cost is proportional to the input after the last token.
+func (sp *separator) separateFrom(s string, rp resumePoint, final bool) (separateResult, error) {
+	result := separateResult{delimiter: sp.delimiter}
+	delimiter := sp.delimiter
+	terminators := activeTerminators(delimiter, sp.terminators)
+
+	// offset is the offset of the current lexer, the lexer is restarted after a terminator which is not a whole token.
+	offset := rp.offset
+
+	// offsetLoc is the location of offset, it is tracked incrementally not to scan s from the beginning.
+	offsetLoc := rp.loc
+	restart := func(newOffset token.Pos) {
+		offsetLoc = advanceLocation(offsetLoc, s[offset:newOffset])
+		offset = newOffset
+	}
+
+	// pos is the head of the current statement.
+	pos := rp.pos
+
+	// end of the last terminator
+	var prevEnd token.Pos
+
+	// tokens of the current statement
+	current := rp.tokens
+
+	// resumed is true while the current statement is the statement of rp.
+	resumed := true
+
+	// undecided is the first head in the current statement at which a terminator may be matched by the following input.
+	undecided := token.InvalidPos
+
+	// setResume sets the resume point of the current statement, it must be called before appendStatement.
+	setResume := func() {
+		// No token is read after rp.
+		if resumed && len(current) == len(rp.tokens) {
+			result.resume = rp
+			return
+		}
+
+		// Resume from the last token, or the token at undecided to match the terminator again.
+		head, tokens := pos, []token.Token(nil)
+		for i := len(current) - 1; i >= 0; i-- {
+			head, tokens = tokenHead(current[i]), current[:i]
+			if undecided.Invalid() || head <= undecided {
+				break
+			}
+		}
+		result.resume = resumePoint{pos: pos, offset: head, loc: advanceLocation(offsetLoc, s[offset:head]), tokens: tokens}
+	}
+
+	appendStatement := func(stmt RawStatement) {
+		result.statements = append(result.statements, stmt)
+		if sp.keepTokens {
+			result.tokens = append(result.tokens, current)
+		}
+		current = nil
+		resumed = false
+		undecided = token.InvalidPos
+	}
+
+	terminate := func(termPos token.Pos, terminator string) {
+		end := termPos + token.Pos(len(terminator))
+		appendStatement(RawStatement{Statement: s[pos:termPos], Pos: pos, End: end, Terminator: terminator})
+		result.consumed = end
+		result.delimiter = delimiter
+		pos = token.InvalidPos
+		prevEnd = end
+	}
+
+lex:
+	for {
+		lexer := newLexer(sp.filepath, s[offset:])
+	tokens:
+		for tok, err := range LexerSeq(lexer) {
+			tok = shiftToken(tok, offset)
+			if err != nil {
+				err = shiftError(err, offsetLoc)
+				if merr, ok := lo.ErrorsAs[*memefish.Error](err); ok {
+					// The lexer may fail at the first token of a statement, so pos can be still invalid.
+					if pos.Invalid() {
+						rest := s[prevEnd:]
+						pos = prevEnd + token.Pos(len(rest)-len(strings.TrimLeftFunc(rest, unicode.IsSpace)))
+					}
+					end := min(merr.Position.End, token.Pos(len(s)))
+					tokens := current
+					setResume()
+					appendStatement(RawStatement{Pos: pos, End: end, Statement: s[pos:end]})
+					return result, toErrLexerStatus(err, s, tokens)
+				}
+				return result, err
+			}
+
+			// renew pos to first comment or first token of a statement.
+			if pos.Invalid() {
+				tokenComment, ok := lo.First(tok.Comments)
+				pos = lo.Ternary(ok, tokenComment.Pos, tok.Pos)
+			}
+
+			if sp.delimiterCommand && len(current) == 0 && tok.IsKeywordLike("DELIMITER") {
+				eol := strings.IndexByte(s[tok.End:], '\n')
+				if eol < 0 && !final {
+					// The delimiter can be continued.
+					setResume()
+					appendStatement(RawStatement{Statement: s[pos:], Pos: pos, End: token.Pos(len(s))})
+					return result, nil
+				}
+
+				lineEnd := lo.Ternary(eol < 0, len(s), int(tok.End)+eol)
+				if newDelimiter := strings.TrimSpace(s[tok.End:lineEnd]); newDelimiter != "" {
+					delimiter = newDelimiter
+					terminators = activeTerminators(delimiter, sp.terminators)
+
+					restart(token.Pos(min(lineEnd+1, len(s))))
+					resumed = false
+					undecided = token.InvalidPos
+					result.consumed = offset
+					result.delimiter = delimiter
+					pos = token.InvalidPos
+					prevEnd = offset
+					continue lex
+				}
+			}
+
+			// Terminators are recognized at the head of comments and tokens.
+			heads := lo.Map(tok.Comments, func(c token.TokenComment, _ int) token.Pos { return c.Pos })
+			if tok.Kind != token.TokenEOF {
+				heads = append(heads, tok.Pos)
+			}
+
+			for _, head := range heads {
+				terminator, ok, isUndecided := matchTerminator(s, head, terminators, final)
+				if isUndecided && undecided.Invalid() {
+					undecided = head
+				}
+				if !ok {
+					continue
+				}
+
+				terminate(head, terminator.Text)
+
+				// No need to restart the lexer if the terminator is the current token.
+				if head == tok.Pos && prevEnd == tok.End {
+					continue tokens
+				}
+
+				restart(prevEnd)
+				continue lex
+			}
+
+			if tok.Kind == token.TokenEOF {
+				// If pos:tok.Pos is not empty, add remaining part of buffer to result.
+				if pos != tok.Pos {
+					setResume()
+					appendStatement(RawStatement{Statement: s[pos:tok.Pos], Pos: pos, End: tok.Pos})
+				}
+				return result, nil
+			}
+			current = append(current, tok)
+		}
+
+		// unreachable because LexerSeq always stops with EOF or an error.
+		return result, fmt.Errorf("BUG: lexer stopped without EOF")
+	}
+}
+
+// matchTerminator returns the longest terminator at pos of s.
+// If final is false, a terminator which can be changed by the following input is not matched, and undecided is true.
+func matchTerminator(s string, pos token.Pos, terminators []Terminator, final bool) (terminator Terminator, ok bool, undecided bool) {
+	rest := s[pos:]
+	for _, terminator := range terminators {
+		if terminator.Text == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(rest, terminator.Text) {
+			// The rest of s can be continued to the terminator.
+			if !final && strings.HasPrefix(terminator.Text, rest) {
+				return Terminator{}, false, true
+			}
+			continue
+		}
+
+		following := rest[len(terminator.Text):]
+
+		// A terminator ending with an identifier character must not be a part of an identifier.
+		if char.IsIdentPart(terminator.Text[len(terminator.Text)-1]) {
+			if following == "" && !final {
+				return Terminator{}, false, true
+			}
+			if following != "" && char.IsIdentPart(following[0]) {
+				continue
+			}
+		}
+
+		if terminator.EndOfLine {
+			eol := strings.IndexByte(following, '\n')
+			if eol < 0 && !final {
+				return Terminator{}, false, true
+			}
+			if strings.TrimSpace(following[:lo.Ternary(eol < 0, len(following), eol)]) != "" {
+				continue
+			}
+		}
+		return terminator, true, false
+	}
+	return Terminator{}, false, false
+}
+
+// tokenHead returns the head of tok including its comments.
+func tokenHead(tok token.Token) token.Pos {
+	if c, ok := lo.First(tok.Comments); ok {
+		return c.Pos
+	}
+	return tok.Pos
+}
+
+// shiftToken shifts positions of tok and its comments by offset.
+func shiftToken(tok token.Token, offset token.Pos) token.Token {
+	if offset == 0 {
+		return tok
+	}
+
+	tok.Pos += offset
+	tok.End += offset
+	tok.Comments = slices.Clone(tok.Comments)
+	for i := range tok.Comments {
+		tok.Comments[i].Pos += offset
+		tok.Comments[i].End += offset
+	}
+	return tok
+}
@@ -0,0 +1,113 @@
+package gsqlutils_test
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSeparateInput(t *testing.T) {
+	verticalTerminator := gsqlutils.Terminator{Text: `\G`, EndOfLine: true}
+	goTerminator := gsqlutils.Terminator{Text: "GO", EndOfLine: true}
+
+	for _, tt := range []struct {
+		desc  string
+		input string
+		opts  []gsqlutils.SeparateOption
+		want  []gsqlutils.RawStatement
+	}{
+		{
+			desc:  "default",
+			input: "SELECT 1;\nSELECT 2",
+			want: []gsqlutils.RawStatement{
+				{Statement: "SELECT 1", Pos: 0, End: 9, Terminator: ";"},
+				{Statement: "SELECT 2", Pos: 10, End: 18},
+			},
+		},
+		{
+			desc:  "vertical terminator",
+			input: "SELECT 1\\G\nSELECT 2;\nSELECT r'\\G'\\G  \n",
+			opts:  []gsqlutils.SeparateOption{gsqlutils.WithTerminators(verticalTerminator)},
+			want: []gsqlutils.RawStatement{
+				{Statement: "SELECT 1", Pos: 0, End: 10, Terminator: `\G`},
+				{Statement: "SELECT 2", Pos: 11, End: 20, Terminator: ";"},
+				{Statement: `SELECT r'\G'`, Pos: 21, End: 35, Terminator: `\G`},
+			},
+		},
+		{
+			desc:  "end of line terminator in the middle of line",
+			input: "SELECT 1\\G SELECT 2;",
+			opts:  []gsqlutils.SeparateOption{gsqlutils.WithTerminators(verticalTerminator)},
+			want: []gsqlutils.RawStatement{
+				{Statement: `SELECT 1\G SELECT 2`, Pos: 0, End: 20, Terminator: ";"},
+			},
+		},
+		{
+			desc:  "identifier-like terminator",
+			input: "SELECT GOOD FROM t\nGO\nSELECT 2",
+			opts:  []gsqlutils.SeparateOption{gsqlutils.WithTerminators(goTerminator)},
+			want: []gsqlutils.RawStatement{
+				{Statement: "SELECT GOOD FROM t\n", Pos: 0, End: 21, Terminator: "GO"},
+				{Statement: "SELECT 2", Pos: 22, End: 30},
+			},
+		},
+		{
+			desc:  "delimiter command",
+			input: "DELIMITER $$\nSELECT 1; SELECT 2$$\nDELIMITER ;\nSELECT 3;",
+			opts:  []gsqlutils.SeparateOption{gsqlutils.WithDelimiterCommand()},
+			want: []gsqlutils.RawStatement{
+				{Statement: "SELECT 1; SELECT 2", Pos: 13, End: 33, Terminator: "$$"},
+				{Statement: "SELECT 3", Pos: 46, End: 55, Terminator: ";"},
+			},
+		},
+		{
+			desc:  "delimiter which starts a comment",
+			input: "DELIMITER //\nSELECT 1; -- comment\nSELECT 2//SELECT 3//",
+			opts:  []gsqlutils.SeparateOption{gsqlutils.WithDelimiterCommand()},
+			want: []gsqlutils.RawStatement{
+				{Statement: "SELECT 1; -- comment\nSELECT 2", Pos: 13, End: 44, Terminator: "//"},
+				{Statement: "SELECT 3", Pos: 44, End: 54, Terminator: "//"},
+			},
+		},
+		{
+			desc:  "delimiter of multiple tokens",
+			input: "DELIMITER ;;;\nSELECT 1; SELECT 2;;;\nSELECT 3;;;",
+			opts:  []gsqlutils.SeparateOption{gsqlutils.WithDelimiterCommand()},
+			want: []gsqlutils.RawStatement{
+				{Statement: "SELECT 1; SELECT 2", Pos: 14, End: 35, Terminator: ";;;"},
+				{Statement: "SELECT 3", Pos: 36, End: 47, Terminator: ";;;"},
+			},
+		},
+		{
+			desc:  "delimiter is an identifier without the option",
+			input: "DELIMITER $$\nSELECT 1;",
+			want: []gsqlutils.RawStatement{
+				{Statement: "DELIMITER $$\nSELECT 1", Pos: 0, End: 22, Terminator: ";"},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.SeparateInput("", tt.input, tt.opts...)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in statements: (-want +got):\n%s", diff)
+			}
+
+			var gotSeq []gsqlutils.RawStatement
+			for stmt, err := range gsqlutils.SeparateReaderSeq("", iotest.OneByteReader(strings.NewReader(tt.input)), tt.opts...) {
+				if err != nil {
+					t.Fatalf("SeparateReaderSeq should success, but failed: %v", err)
+				}
+				gotSeq = append(gotSeq, stmt)
+			}
+			if diff := cmp.Diff(tt.want, gotSeq); diff != "" {
+				t.Errorf("difference in statements of SeparateReaderSeq: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package gsqlutils
+
+import (
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils/internal"
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+type tokenList []token.Token
+
+func (t tokenList) prevKind(prevNth int) token.TokenKind {
+	return prevKind(t, prevNth)
+}
+
+// spacing is a kind of separator between two tokens.
+type spacing int
+
+const (
+	spacingNone spacing = iota
+	spacingSpace
+
+	// spacingStatement is a separator between statements.
+	spacingStatement
+)
+
+// spacer decides separators between tokens in a token sequence.
+// It is shared by SimpleStripComments, SimpleSkipHints and Format.
+// A spacer can be copied to look ahead without changing the original state.
+type spacer struct {
+	// tokens are tokens of the current statement.
+	tokens tokenList
+
+	prev token.Token
+
+	// started is true if any token is passed.
+	started bool
+
+	// hints tracks hints including nested braces
+	hints tokenfilter.HintScanner
+
+	// Count "<" level in compound type
+	compoundTypeLevel int
+}
+
+func newSpacer() *spacer {
+	return &spacer{prev: token.Token{Pos: token.InvalidPos, End: token.InvalidPos}}
+}
+
+// next returns the separator before tok, and updates the state.
+func (s *spacer) next(tok token.Token) spacing {
+	prev := s.prev
+
+	if (s.compoundTypeLevel > 0 || prev.Kind == "ARRAY" || prev.Kind == "STRUCT") && tok.Kind == "<" {
+		s.compoundTypeLevel++
+	}
+
+	// Errors of malformed hints are ignored, spacing is decided as best effort basis.
+	hintRole, _ := s.hints.Next(tok)
+	inHint := hintRole != tokenfilter.HintRoleNone
+
+	result := spacingNone
+	if s.started {
+		switch {
+		// first token after semicolon
+		case prev.Kind == ";":
+			result = spacingStatement
+		// after open or dot
+		case internal.OneOf(prev.Kind, "(", "{", "[", "."),
+			// before close or dot, comma, colon
+			internal.OneOf(tok.Kind, ")", "}", "]", ".", ",", ":", ";"),
+			// hint
+			tok.Kind == "@" && internal.OneOf(prev.Kind, ")", token.TokenIdent),
+			prev.Kind == "@" && tok.Kind == "{",
+			internal.OneOf(prev.Kind, "@") && internal.OneOf(tok.Kind, "{"),
+			inHint && prev.Kind == "=",
+			inHint && tok.Kind == "=",
+
+			// system variable
+			prev.Kind == "@@",
+
+			// '<' & '>' in compound types
+			internal.OneOf(prev.Kind, "STRUCT", "ARRAY") && internal.OneOf(tok.Kind, "<", "<>"),
+			s.compoundTypeLevel > 0 && prev.Kind == "<",
+			s.compoundTypeLevel > 0 && internal.OneOf(tok.Kind, ">", ">>"),
+
+			// function like keyword
+			tok.Kind == "(" && internal.OneOf(prev.Kind, "UNNEST", "WITH", "STRUCT", "ARRAY", "CAST"),
+
+			// subscript expression, and array literal with ARRAY keyword
+			tok.Kind == "[" && (isOperandEnd(prev.Kind) || prev.Kind == "ARRAY"),
+
+			// unary minus, but "- -" must not be joined into a comment
+			prev.Kind == "-" && !isOperandEnd(s.tokens.prevKind(2)) && tok.Kind != "-",
+
+			// "identifier(" can be function calls, it is natural not to be separated by whitespace, preserve original.
+			// Note: STORING () should be separated by whitespaces
+			tok.Kind == "(" && prev.Kind == token.TokenIdent &&
+				!prev.IsKeywordLike("STORING") &&
+				tok.Pos == prev.End:
+			result = spacingNone
+		default:
+			result = spacingSpace
+		}
+	}
+
+	if s.compoundTypeLevel > 0 {
+		switch {
+		case tok.Kind == ">":
+			s.compoundTypeLevel -= 1
+		case tok.Kind == ">>":
+			s.compoundTypeLevel -= 2
+		}
+	}
+
+	s.prev = tok
+	s.started = true
+
+	if tok.Kind == ";" {
+		s.tokens = tokenList(nil)
+	}
+	s.tokens = append(s.tokens, tok)
+	return result
+}
@@ -0,0 +1,168 @@
+package gsqlutils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/char"
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/samber/lo"
+)
+
+// ConstructKind is a kind of construct which can be unclosed.
+type ConstructKind int
+
+const (
+	ConstructInvalid ConstructKind = iota
+
+	// ConstructString is a triple-quoted string or bytes literal.
+	ConstructString
+
+	// ConstructComment is a block comment.
+	ConstructComment
+
+	// ConstructParen is a parenthesis `(`.
+	ConstructParen
+
+	// ConstructBracket is a square bracket `[`.
+	ConstructBracket
+
+	// ConstructBrace is a curly brace `{` which is not a hint.
+	ConstructBrace
+
+	// ConstructTypeParameter is a type parameter of a compound type, e.g. `ARRAY<`, `STRUCT<`.
+	ConstructTypeParameter
+
+	// ConstructHint is a hint `@{`.
+	ConstructHint
+)
+
+func (k ConstructKind) String() string {
+	switch k {
+	case ConstructString:
+		return "String"
+	case ConstructComment:
+		return "Comment"
+	case ConstructParen:
+		return "Paren"
+	case ConstructBracket:
+		return "Bracket"
+	case ConstructBrace:
+		return "Brace"
+	case ConstructTypeParameter:
+		return "TypeParameter"
+	case ConstructHint:
+		return "Hint"
+	case ConstructInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(k))
+	}
+}
+
+// OpenConstruct is an unclosed construct.
+type OpenConstruct struct {
+	Kind ConstructKind
+
+	// Opening is the opening string including prefixes, e.g. `r'''`, `b"""`, `/*`, `(`, `ARRAY<`, `@{`.
+	Opening string
+
+	// Closing is the string to close the construct, e.g. `'''`, `*/`, `)`, `>`, `}`.
+	Closing string
+
+	// Location is the head of Opening.
+	Location Location
+}
+
+// OpenConstructs returns the stack of unclosed constructs at the end of s, the outermost first.
+// Unlike SeparateInput, it also reports unclosed brackets, compound types and hints of the last statement.
+// It returns nil if the last statement is terminated or has no unclosed construct.
+// filepath can be empty, it is only used in error message.
+func OpenConstructs(filepath, s string, opts ...SeparateOption) ([]OpenConstruct, error) {
+	sp := newSeparator(filepath, opts...)
+	sp.keepTokens = true
+
+	result, err := sp.separate(s, true)
+	if status, ok := lo.ErrorsAs[*ErrLexerStatus](err); ok {
+		return status.Open, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	last := len(result.statements) - 1
+	if last < 0 || result.statements[last].Terminator != "" {
+		return nil, nil
+	}
+	return openBrackets(result.tokens[last], lazyPositionIndex(s)), nil
+}
+
+// openBrackets returns the stack of unclosed brackets, compound types and hints in tokens, the outermost first.
+// Unmatched closing tokens are ignored.
+// newIndex is called only if there is an unclosed construct.
+func openBrackets(tokens []token.Token, newIndex func() *PositionIndex) []OpenConstruct {
+	var stack []OpenConstruct
+	push := func(kind ConstructKind, pos token.Pos, opening, closing string) {
+		stack = append(stack, OpenConstruct{Kind: kind, Opening: opening, Closing: closing, Location: newIndex().Location(pos)})
+	}
+
+	// pop pops the top of stack if it is closed by closing.
+	pop := func(closing string) {
+		if top, ok := lo.Last(stack); ok && top.Closing == closing {
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	var prev token.Token
+	for _, tok := range tokens {
+		switch {
+		case tok.Kind == "(":
+			push(ConstructParen, tok.Pos, "(", ")")
+		case tok.Kind == "[":
+			push(ConstructBracket, tok.Pos, "[", "]")
+		case tok.Kind == "{" && prev.Kind == "@":
+			push(ConstructHint, prev.Pos, "@{", "}")
+		case tok.Kind == "{":
+			push(ConstructBrace, tok.Pos, "{", "}")
+		case tok.Kind == "<" && (prev.Kind == "ARRAY" || prev.Kind == "STRUCT"):
+			push(ConstructTypeParameter, prev.Pos, prev.Raw+"<", ">")
+		case tok.Kind == ")" || tok.Kind == "]" || tok.Kind == "}" || tok.Kind == ">":
+			pop(string(tok.Kind))
+		case tok.Kind == ">>":
+			pop(">")
+			pop(">")
+		}
+		prev = tok
+	}
+
+	if len(stack) == 0 {
+		return nil
+	}
+	return stack
+}
+
+// lexerConstruct returns the construct which the lexer is waiting at the error.
+// Location is resolved only if the construct is found, because it needs to scan s.
+func lexerConstruct(err *LexerError, s string, newIndex func() *PositionIndex) (OpenConstruct, bool) {
+	pos := min(err.Err.Position.Pos, token.Pos(len(s)))
+	head := s[pos:]
+
+	switch {
+	case errors.Is(err, ErrUnclosedString) && (strings.HasPrefix(head, `"""`) || strings.HasPrefix(head, "'''")):
+		// The error is at the quote, so r and b prefixes are before it.
+		start := pos
+		for start > 0 && pos-start < 2 && strings.ContainsRune("rRbB", rune(s[start-1])) {
+			start--
+		}
+		if start > 0 && char.IsIdentPart(s[start-1]) {
+			start = pos
+		}
+		quote := head[:3]
+		return OpenConstruct{Kind: ConstructString, Opening: s[start:pos] + quote, Closing: quote, Location: newIndex().Location(start)}, true
+	case errors.Is(err, ErrUnclosedComment):
+		return OpenConstruct{Kind: ConstructComment, Opening: "/*", Closing: "*/", Location: newIndex().Location(pos)}, true
+	default:
+		return OpenConstruct{}, false
+	}
+}
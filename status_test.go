@@ -0,0 +1,82 @@
+package gsqlutils_test
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+)
+
+func TestOpenConstructs(t *testing.T) {
+	// loc returns a location in the first line of an ASCII input.
+	loc := func(pos int) gsqlutils.Location {
+		return gsqlutils.Location{Pos: token.Pos(pos), Column: pos, RuneColumn: pos, UTF16Column: pos}
+	}
+
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  []gsqlutils.OpenConstruct
+	}{
+		{
+			desc:  "complete",
+			input: "SELECT (1);",
+		},
+		{
+			desc:  "unterminated but closed",
+			input: "SELECT ARRAY<STRUCT<x INT64>>[]",
+		},
+		{
+			desc:  "raw triple-quoted string in brackets",
+			input: "SELECT ([r'''a",
+			want: []gsqlutils.OpenConstruct{
+				{Kind: gsqlutils.ConstructParen, Opening: "(", Closing: ")", Location: loc(7)},
+				{Kind: gsqlutils.ConstructBracket, Opening: "[", Closing: "]", Location: loc(8)},
+				{Kind: gsqlutils.ConstructString, Opening: "r'''", Closing: "'''", Location: loc(9)},
+			},
+		},
+		{
+			desc:  "bytes triple-quoted string",
+			input: `SELECT b"""a`,
+			want: []gsqlutils.OpenConstruct{
+				{Kind: gsqlutils.ConstructString, Opening: `b"""`, Closing: `"""`, Location: loc(7)},
+			},
+		},
+		{
+			desc:  "comment in a hint",
+			input: "SELECT * FROM t@{FORCE_INDEX=/* idx",
+			want: []gsqlutils.OpenConstruct{
+				{Kind: gsqlutils.ConstructHint, Opening: "@{", Closing: "}", Location: loc(15)},
+				{Kind: gsqlutils.ConstructComment, Opening: "/*", Closing: "*/", Location: loc(29)},
+			},
+		},
+		{
+			desc:  "compound types",
+			input: "SELECT CAST(NULL AS ARRAY<STRUCT<x STRING(10)",
+			want: []gsqlutils.OpenConstruct{
+				{Kind: gsqlutils.ConstructParen, Opening: "(", Closing: ")", Location: loc(11)},
+				{Kind: gsqlutils.ConstructTypeParameter, Opening: "ARRAY<", Closing: ">", Location: loc(20)},
+				{Kind: gsqlutils.ConstructTypeParameter, Opening: "STRUCT<", Closing: ">", Location: loc(26)},
+			},
+		},
+		{
+			desc:  "only the last statement",
+			input: "SELECT (1; SELECT {",
+			want: []gsqlutils.OpenConstruct{
+				{Kind: gsqlutils.ConstructBrace, Opening: "{", Closing: "}", Location: loc(18)},
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.OpenConstructs("", tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in constructs: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
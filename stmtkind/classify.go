@@ -0,0 +1,74 @@
+package stmtkind
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/apstndb/gsqlutils/clientstmt"
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+// ClassifiedStatement is a statement with its StatementKind.
+type ClassifiedStatement struct {
+	gsqlutils.TokenizedStatement
+
+	Kind StatementKind
+
+	// FirstToken is the first non-hint token of the statement.
+	// It is EOF if the statement has no token.
+	FirstToken token.Token
+
+	// ClientSide is the parsed client-side statement, it is not nil only if Kind is StatementKindClientSide.
+	ClientSide *clientstmt.Statement
+
+	// Err is an error on detection of Kind. Kind is StatementKindInvalid if Err is not nil.
+	Err error
+}
+
+// ClassifyInput separates an input string to statements like gsqlutils.SeparateInput and detects StatementKind of each statement.
+// It reuses tokens of gsqlutils.SeparateInputTokens, so it doesn't lex statements again.
+// The returned error is an error on separation, errors on detection are stored in ClassifiedStatement.Err.
+// filepath can be empty, it is only used in error message.
+func ClassifyInput(filepath, s string, opts ...gsqlutils.SeparateOption) ([]ClassifiedStatement, error) {
+	return ClassifyInputWithRegistry(filepath, s, nil, opts...)
+}
+
+// ClassifyInputWithRegistry is same as ClassifyInput, but it also detects client-side statements in registry.
+// Client-side statements are detected before GoogleSQL statements. If registry is nil, it is same as ClassifyInput.
+// filepath can be empty, it is only used in error message.
+func ClassifyInputWithRegistry(filepath, s string, registry *clientstmt.Registry, opts ...gsqlutils.SeparateOption) ([]ClassifiedStatement, error) {
+	stmts, err := gsqlutils.SeparateInputTokens(filepath, s, opts...)
+
+	results := make([]ClassifiedStatement, 0, len(stmts))
+	for _, stmt := range stmts {
+		results = append(results, classify(stmt, registry))
+	}
+	return results, err
+}
+
+func classify(stmt gsqlutils.TokenizedStatement, registry *clientstmt.Registry) ClassifiedStatement {
+	result := ClassifiedStatement{TokenizedStatement: stmt}
+
+	next, stop := iter.Pull2(tokenfilter.StripHints(stmt.TokenSeq()))
+	defer stop()
+
+	tok, err, _ := next()
+	result.FirstToken = tok
+	if err != nil {
+		result.Err = fmt.Errorf("can't get first token, err: %w", err)
+		return result
+	}
+
+	if registry != nil && tok.Kind != token.TokenEOF {
+		if cstmt, ok := registry.Parse(stmt.Statement); ok {
+			result.Kind, result.ClientSide = StatementKindClientSide, &cstmt
+			return result
+		}
+	}
+
+	result.Kind, result.Err = detectFirstToken(tok)
+	return result
+}
@@ -0,0 +1,97 @@
+package stmtkind_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils/clientstmt"
+	"github.com/apstndb/gsqlutils/stmtkind"
+)
+
+func TestClassifyInput(t *testing.T) {
+	const input = `CREATE TABLE t (pk INT64) PRIMARY KEY (pk);
+@{OPTIMIZER_VERSION=7} SELECT * FROM t;
+INSERT INTO t (pk) VALUES (1);
+-- comment only
+CALL cancel_query("1");
+GRAPH FinGraph MATCH (n) RETURN n;
+UNKNOWN STATEMENT;
+@{unclosed_hint=TRUE`
+
+	type result struct {
+		Kind       stmtkind.StatementKind
+		FirstToken string
+		HasErr     bool
+	}
+
+	want := []result{
+		{Kind: stmtkind.StatementKindDDL, FirstToken: "CREATE"},
+		{Kind: stmtkind.StatementKindQuery, FirstToken: "SELECT"},
+		{Kind: stmtkind.StatementKindDML, FirstToken: "INSERT"},
+		{Kind: stmtkind.StatementKindCall, FirstToken: "CALL"},
+		{Kind: stmtkind.StatementKindGraph, FirstToken: "GRAPH"},
+		{Kind: stmtkind.StatementKindInvalid, FirstToken: "UNKNOWN", HasErr: true},
+		{Kind: stmtkind.StatementKindInvalid, FirstToken: "", HasErr: true},
+	}
+
+	stmts, err := stmtkind.ClassifyInput("", input)
+	if err != nil {
+		t.Fatalf("should success, but failed: %v", err)
+	}
+
+	var got []result
+	for _, stmt := range stmts {
+		got = append(got, result{Kind: stmt.Kind, FirstToken: stmt.FirstToken.Raw, HasErr: stmt.Err != nil})
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in kinds: (-want +got):\n%s", diff)
+	}
+}
+
+func TestClassifyInputWithRegistry(t *testing.T) {
+	const input = `START BATCH DDL;
+CREATE TABLE t (pk INT64) PRIMARY KEY (pk);
+RUN BATCH;
+EXPLAIN ANALYZE SELECT 1;
+ANALYZE;
+SHOW UNKNOWN;`
+
+	type result struct {
+		Kind       stmtkind.StatementKind
+		ClientSide string
+		HasErr     bool
+	}
+
+	want := []result{
+		{Kind: stmtkind.StatementKindClientSide, ClientSide: "START BATCH"},
+		{Kind: stmtkind.StatementKindDDL},
+		{Kind: stmtkind.StatementKindClientSide, ClientSide: "RUN BATCH"},
+		{Kind: stmtkind.StatementKindClientSide, ClientSide: "EXPLAIN"},
+		{Kind: stmtkind.StatementKindDDL},
+		{Kind: stmtkind.StatementKindInvalid, HasErr: true},
+	}
+
+	stmts, err := stmtkind.ClassifyInputWithRegistry("", input, clientstmt.DefaultRegistry())
+	if err != nil {
+		t.Fatalf("should success, but failed: %v", err)
+	}
+
+	var got []result
+	for _, stmt := range stmts {
+		r := result{Kind: stmt.Kind, HasErr: stmt.Err != nil}
+		if stmt.ClientSide != nil {
+			r.ClientSide = stmt.ClientSide.Name
+		}
+		got = append(got, r)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("difference in kinds: (-want +got):\n%s", diff)
+	}
+
+	if stmtkind.StatementKindClientSide.IsExecuteSQLCompatible() {
+		t.Error("client-side statement should not be compatible with ExecuteSQL API")
+	}
+}
@@ -19,6 +19,10 @@ const (
 	// but it is a compatible with ExecuteSQL API..
 	// https://cloud.google.com/spanner/docs/reference/standard-sql/graph-query-statementsl
 	StatementKindGraph
+
+	// StatementKindClientSide is a client-side statement which is handled by drivers and CLIs, e.g. `SHOW VARIABLE`.
+	// It is not sent to Spanner. See also clientstmt package.
+	StatementKindClientSide
 )
 
 func (k StatementKind) String() string {
@@ -33,6 +37,8 @@ func (k StatementKind) String() string {
 		return "CALL"
 	case StatementKindGraph:
 		return "Graph"
+	case StatementKindClientSide:
+		return "ClientSide"
 	case StatementKindInvalid:
 		return "Invalid"
 	default:
@@ -64,11 +70,15 @@ func (k StatementKind) IsGraph() bool {
 	return k == StatementKindGraph
 }
 
+func (k StatementKind) IsClientSide() bool {
+	return k == StatementKindClientSide
+}
+
 // IsExecuteSQLCompatible is true when it is compatible with ExecuteSQL API family.
 // Note: It is true if it is one of query, DML, Procedural(CALL), GRAPH statements,
-// it means all statements except DDL statements.
+// it means all statements except DDL and client-side statements.
 func (k StatementKind) IsExecuteSQLCompatible() bool {
-	return !k.IsInvalid() && !k.IsDDL()
+	return !k.IsInvalid() && !k.IsDDL() && !k.IsClientSide()
 }
 
 // IsUpdateDDLCompatible is true when it is compatible with UpdateDatabaseDdl API and CreateDatabase API.
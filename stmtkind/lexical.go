@@ -6,20 +6,16 @@ import (
 	"github.com/cloudspannerecosystem/memefish/token"
 
 	"github.com/apstndb/gsqlutils"
+	"github.com/apstndb/gsqlutils/clientstmt"
+	"github.com/apstndb/gsqlutils/keywords"
 )
 
 var kindFirstTokensMap = map[StatementKind][]string{
-	// Current prefixes of DDL statements
-	// https://cloud.google.com/spanner/docs/reference/standard-sql/data-definition-language
-	StatementKindDDL: {"CREATE", "ALTER", "DROP", "RENAME", "GRANT", "REVOKE", "ANALYZE"},
-	StatementKindDML: {"INSERT", "DELETE", "UPDATE"},
-
-	// It starts with "WITH" of CTE or query expression, it can be a ZetaSQL FROM query.
-	// https://cloud.google.com/spanner/docs/reference/standard-sql/query-syntax#sql_syntax
-	// https://github.com/google/zetasql/blob/master/docs/pipe-syntax.md#from-queries
-	StatementKindQuery: {"SELECT", "WITH", "(", "FROM"},
-	StatementKindGraph: {"GRAPH"},
-	StatementKindCall:  {"CALL"},
+	StatementKindDDL:   keywords.DDLFirstTokens(),
+	StatementKindDML:   keywords.DMLFirstTokens(),
+	StatementKindQuery: keywords.QueryFirstTokens(),
+	StatementKindGraph: keywords.GraphFirstTokens(),
+	StatementKindCall:  keywords.CallFirstTokens(),
 }
 
 func DetectLexical(s string) (StatementKind, error) {
@@ -28,8 +24,27 @@ func DetectLexical(s string) (StatementKind, error) {
 		return StatementKindInvalid, err
 	}
 
+	return detectFirstToken(tok)
+}
+
+// DetectLexicalWithRegistry is same as DetectLexical, but it detects client-side statements in registry before GoogleSQL statements.
+// The parsed client-side statement is returned only if the kind is StatementKindClientSide.
+// If registry is nil, it is same as DetectLexical.
+func DetectLexicalWithRegistry(s string, registry *clientstmt.Registry) (StatementKind, *clientstmt.Statement, error) {
+	if registry != nil {
+		if stmt, ok := registry.Parse(s); ok {
+			return StatementKindClientSide, &stmt, nil
+		}
+	}
+
+	kind, err := DetectLexical(s)
+	return kind, nil, err
+}
+
+// detectFirstToken detects StatementKind by the first non-hint token.
+func detectFirstToken(tok token.Token) (StatementKind, error) {
 	for kind, tokens := range kindFirstTokensMap {
-		if oneOfKeywordLikeFuzzy(tok, tokens...) {
+		if keywords.MatchAny(tok, tokens...) {
 			return kind, nil
 		}
 	}
@@ -60,21 +75,3 @@ func IsUpdateDDLCompatibleLexical(s string) bool {
 func ignoreLast[T1, T2 any](v1 T1, _ T2) T1 {
 	return v1
 }
-
-// isKeywordLikeFuzzy is true when tok.IsKeywordLike(keywordLike) or tok.Kind == keywordLike
-func isKeywordLikeFuzzy(tok token.Token, keywordLike string) bool {
-	if tok.Kind == token.TokenIdent {
-		return tok.IsKeywordLike(keywordLike)
-	}
-	return tok.Kind == token.TokenKind(keywordLike)
-}
-
-// oneOfKeywordLikeFuzzy is true when tok is a one of keywordLikes.
-func oneOfKeywordLikeFuzzy(tok token.Token, keywordLikes ...string) bool {
-	for _, k := range keywordLikes {
-		if isKeywordLikeFuzzy(tok, k) {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,48 @@
+package stmtkind_test
+
+import (
+	"testing"
+
+	"github.com/apstndb/gsqlutils/clientstmt"
+	"github.com/apstndb/gsqlutils/stmtkind"
+)
+
+func TestDetectLexicalWithRegistry(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		input    string
+		registry *clientstmt.Registry
+		want     stmtkind.StatementKind
+	}{
+		{
+			desc:     "client-side statement",
+			input:    "START BATCH DDL",
+			registry: clientstmt.DefaultRegistry(),
+			want:     stmtkind.StatementKindClientSide,
+		},
+		{
+			desc:     "GoogleSQL statement",
+			input:    "SELECT 1",
+			registry: clientstmt.DefaultRegistry(),
+			want:     stmtkind.StatementKindQuery,
+		},
+		{
+			desc:  "nil registry",
+			input: "ANALYZE",
+			want:  stmtkind.StatementKindDDL,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, stmt, err := stmtkind.DetectLexicalWithRegistry(tt.input, tt.registry)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("want %v, but got %v", tt.want, got)
+			}
+			if (stmt != nil) != (tt.want == stmtkind.StatementKindClientSide) {
+				t.Errorf("client-side statement should be returned only for StatementKindClientSide, but got %v", stmt)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package tokenfilter
+
+import (
+	"iter"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+
+	"github.com/apstndb/gsqlutils/keywords"
+)
+
+// KeywordCase is a letter case of keywords.
+type KeywordCase int
+
+const (
+	KeywordCaseUpper KeywordCase = iota
+	KeywordCaseLower
+)
+
+func (c KeywordCase) apply(s string) string {
+	if c == KeywordCaseLower {
+		return strings.ToLower(s)
+	}
+	return strings.ToUpper(s)
+}
+
+// NormalizeKeywordCase rewrites Raw of reserved keywords, and unquoted identifiers which match one of keywordLikes case-insensitively, to keywordCase.
+// Other tokens, comments and tokens in hints are not changed. Positions of tokens are not changed.
+func NormalizeKeywordCase(seq iter.Seq2[token.Token, error], keywordCase KeywordCase, keywordLikes ...string) iter.Seq2[token.Token, error] {
+	return func(yield func(token.Token, error) bool) {
+		// Malformed hints are not errors of this filter, so errors of HintScanner are ignored.
+		var hints HintScanner
+		for tok, err := range seq {
+			if err != nil {
+				_ = yield(tok, err)
+				return
+			}
+
+			if role, _ := hints.Next(tok); role == HintRoleNone && isKeywordOrKeywordLike(tok, keywordLikes) {
+				tok.Raw = keywordCase.apply(tok.Raw)
+			}
+
+			if !yield(tok, nil) {
+				return
+			}
+		}
+	}
+}
+
+func isKeywordOrKeywordLike(tok token.Token, keywordLikes []string) bool {
+	return keywords.IsKeywordToken(tok) || (tok.Kind == token.TokenIdent && keywords.MatchAny(tok, keywordLikes...))
+}
@@ -0,0 +1,230 @@
+package tokenfilter
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// HintRole is a role of a token in hints.
+type HintRole int
+
+const (
+	// HintRoleNone is a token which is not a part of hints.
+	HintRoleNone HintRole = iota
+
+	// HintRoleAt is "@" which starts a hint.
+	HintRoleAt
+
+	// HintRoleOpen is "{" which follows HintRoleAt.
+	HintRoleOpen
+
+	// HintRoleBody is a token between HintRoleOpen and HintRoleClose.
+	HintRoleBody
+
+	// HintRoleClose is "}" which closes a hint.
+	HintRoleClose
+)
+
+func (r HintRole) String() string {
+	switch r {
+	case HintRoleNone:
+		return "None"
+	case HintRoleAt:
+		return "At"
+	case HintRoleOpen:
+		return "Open"
+	case HintRoleBody:
+		return "Body"
+	case HintRoleClose:
+		return "Close"
+	default:
+		return fmt.Sprintf("UNKNOWN(%v)", int(r))
+	}
+}
+
+// HintToken is a token with its role in hints.
+type HintToken struct {
+	token.Token
+	Role HintRole
+}
+
+// HintError is an error of a malformed or unclosed hint.
+type HintError struct {
+	// At is "@" which starts the hint.
+	At token.Token
+
+	// Tok is the token where the error is detected.
+	Tok token.Token
+
+	Message string
+
+	// Err is the underlying error, e.g. a lexer error in the hint.
+	Err error
+
+	// Position and AtPosition are the resolved positions of Tok and At.
+	// They are nil until Locate is called, and offsets are reported instead.
+	Position, AtPosition *token.Position
+}
+
+// Locate resolves positions of the error in file, which is the file tokens are lexed from.
+func (e *HintError) Locate(file *token.File) {
+	e.Position = file.Position(e.Tok.Pos, e.Tok.End)
+	e.AtPosition = file.Position(e.At.Pos, e.At.End)
+}
+
+func (e *HintError) Error() string {
+	var msg string
+	if e.Position != nil && e.AtPosition != nil {
+		msg = fmt.Sprintf("%v: %v, hint started at %v:%v", e.Position, e.Message, e.AtPosition.Line+1, e.AtPosition.Column+1)
+	} else {
+		msg = fmt.Sprintf("%v at offset %v, hint started at offset %v", e.Message, e.Tok.Pos, e.At.Pos)
+	}
+
+	if e.Err != nil {
+		msg += fmt.Sprintf(", err: %v", e.Err)
+	}
+	return msg
+}
+
+// LocateHintErrors calls HintError.Locate on all HintErrors in seq with file.
+func LocateHintErrors[T any](file *token.File, seq iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for v, err := range seq {
+			var hintErr *HintError
+			if errors.As(err, &hintErr) && hintErr.Position == nil {
+				hintErr.Locate(file)
+			}
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
+
+func (e *HintError) Unwrap() error {
+	return e.Err
+}
+
+// HintScanner is a state machine which scans hints token by token. The zero value is ready to use.
+// A hint starts with "@" followed by "{", and ends with the "}" which balances the "{",
+// so braces, parentheses and brackets can be nested in a hint, e.g. @{a={b=(1)}} ends at the last "}".
+// A HintScanner can be copied to look ahead without changing the original state.
+type HintScanner struct {
+	prev token.Token
+
+	// at is "@" of the current hint.
+	at token.Token
+
+	// closers is a stack of expected closing tokens in the current hint, the innermost last.
+	// It is a string to be safely copied.
+	closers string
+}
+
+// InHint is true if the last token passed to Next is in a hint except the closing "}".
+func (s *HintScanner) InHint() bool {
+	return len(s.closers) > 0
+}
+
+// Next updates the state with tok, which must follow the token of the previous call, and returns the role of tok.
+// "@" is always HintRoleNone because it can't be determined until the next token;
+// "{" after it is HintRoleOpen, so callers which need the role of "@" hold it like AnnotateHints.
+//
+// It returns a *HintError if tok is EOF or ";" in a hint, or a closing token which doesn't match.
+// The state is recovered even on errors, so callers which ignore errors don't treat the rest of the input as a hint:
+// EOF and ";" end the hint, and a mismatched closing token closes constructs up to the one it matches, if any.
+func (s *HintScanner) Next(tok token.Token) (HintRole, error) {
+	prev := s.prev
+	s.prev = tok
+
+	if !s.InHint() {
+		if prev.Kind == "@" && tok.Kind == "{" {
+			s.at = prev
+			s.closers = "}"
+			return HintRoleOpen, nil
+		}
+		return HintRoleNone, nil
+	}
+
+	switch tok.Kind {
+	case token.TokenEOF, ";":
+		s.closers = ""
+		return HintRoleNone, &HintError{At: s.at, Tok: tok, Message: "unclosed hint"}
+	case "{":
+		s.closers += "}"
+	case "(":
+		s.closers += ")"
+	case "[":
+		s.closers += "]"
+	case "}", ")", "]":
+		var err error
+		if want := s.closers[len(s.closers)-1:]; string(tok.Kind) != want {
+			err = &HintError{At: s.at, Tok: tok, Message: fmt.Sprintf("unexpected %v in hint, want %v", tok.Kind, want)}
+		}
+
+		// Close constructs up to the one which tok matches. A closing token without its opening token is ignored.
+		i := strings.LastIndex(s.closers, string(tok.Kind))
+		if i < 0 {
+			return HintRoleBody, err
+		}
+
+		s.closers = s.closers[:i]
+		if !s.InHint() {
+			return HintRoleClose, err
+		}
+		return HintRoleBody, err
+	}
+	return HintRoleBody, nil
+}
+
+// AnnotateHints annotates tokens with their roles in hints using HintScanner.
+// "@" is held until the next token, because it is a hint only if it is followed by "{".
+// It yields a *HintError with the token which stops the sequence if a hint is malformed or not closed.
+func AnnotateHints(seq iter.Seq2[token.Token, error]) iter.Seq2[HintToken, error] {
+	return func(yield func(HintToken, error) bool) {
+		var scanner HintScanner
+
+		// Temporary preserved "@" token, it will be released immediately on next token.
+		var undeterminedAt *token.Token
+
+		for tok, err := range seq {
+			role := HintRoleNone
+			if err == nil {
+				role, err = scanner.Next(tok)
+			} else if scanner.InHint() {
+				role = HintRoleBody
+				err = &HintError{At: scanner.at, Tok: tok, Message: "unclosed hint with error", Err: err}
+			}
+
+			if undeterminedAt != nil {
+				at := HintToken{Token: *undeterminedAt}
+				undeterminedAt = nil
+				if role == HintRoleOpen {
+					at.Role = HintRoleAt
+				}
+
+				if !yield(at, nil) {
+					return
+				}
+			}
+
+			switch {
+			case err != nil:
+				_ = yield(HintToken{Token: tok, Role: role}, err)
+				return
+			case tok.Kind == token.TokenEOF:
+				_ = yield(HintToken{Token: tok}, nil)
+				return
+			case tok.Kind == "@" && role == HintRoleNone:
+				undeterminedAt = &tok
+			default:
+				if !yield(HintToken{Token: tok, Role: role}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
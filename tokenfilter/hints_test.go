@@ -0,0 +1,146 @@
+package tokenfilter_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/apstndb/gsqlutils"
+	"github.com/apstndb/gsqlutils/tokenfilter"
+)
+
+// scanRoles returns "raw:role" of tokens in s scanned by HintScanner, and messages of errors.
+func scanRoles(t *testing.T, s string) ([]string, []string) {
+	t.Helper()
+
+	var roles, errs []string
+	var scanner tokenfilter.HintScanner
+	for tok, err := range gsqlutils.NewLexerSeq("", s) {
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+
+		role, err := scanner.Next(tok)
+		if err != nil {
+			var hintErr *tokenfilter.HintError
+			if !errors.As(err, &hintErr) {
+				t.Fatalf("want HintError, but got %v", err)
+			}
+			errs = append(errs, hintErr.Message)
+		}
+		roles = append(roles, tok.Raw+":"+role.String())
+	}
+	return roles, errs
+}
+
+func TestHintScanner(t *testing.T) {
+	for _, tt := range []struct {
+		desc      string
+		input     string
+		wantRoles string
+		wantErrs  []string
+	}{
+		{
+			desc:      "no hint",
+			input:     "SELECT @p, {a: 1}",
+			wantRoles: "SELECT:None @p:None ,:None {:None a:None ::None 1:None }:None :None",
+		},
+		{
+			desc:      "nested braces",
+			input:     "@{a={b=(1)}} SELECT 1",
+			wantRoles: "@:None {:Open a:Body =:Body {:Body b:Body =:Body (:Body 1:Body ):Body }:Body }:Close SELECT:None 1:None :None",
+		},
+		{
+			desc:      "mismatched closing recovers at the hint end",
+			input:     "t@{a=(x]} WHERE '1'",
+			wantRoles: "t:None @:None {:Open a:Body =:Body (:Body x:Body ]:Body }:Close WHERE:None '1':None :None",
+			wantErrs:  []string{"unexpected ] in hint, want )", "unexpected } in hint, want )"},
+		},
+		{
+			desc:      "semicolon ends unclosed hint",
+			input:     "@{a=1; SELECT '1'",
+			wantRoles: "@:None {:Open a:Body =:Body 1:Body ;:None SELECT:None '1':None :None",
+			wantErrs:  []string{"unclosed hint"},
+		},
+		{
+			desc:      "EOF in hint",
+			input:     "@{a=1",
+			wantRoles: "@:None {:Open a:Body =:Body 1:Body :None",
+			wantErrs:  []string{"unclosed hint"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			roles, errs := scanRoles(t, tt.input)
+			if diff := cmp.Diff(tt.wantRoles, strings.Join(roles, " ")); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantErrs, errs); diff != "" {
+				t.Errorf("difference in errors: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHintScannerCopy(t *testing.T) {
+	var scanner tokenfilter.HintScanner
+	var tokens []string
+	for tok, err := range gsqlutils.NewLexerSeq("", "@{a=(1) } SELECT 1") {
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+
+		// Scanning the rest with a copy must not change the original.
+		if tok.Raw == "(" {
+			lookahead := scanner
+			_, _ = lookahead.Next(tok)
+			_, _ = lookahead.Next(tok)
+		}
+
+		role, err := scanner.Next(tok)
+		if err != nil {
+			t.Fatalf("should success, but failed: %v", err)
+		}
+		tokens = append(tokens, tok.Raw+":"+role.String())
+	}
+
+	want := "@:None {:Open a:Body =:Body (:Body 1:Body ):Body }:Close SELECT:None 1:None :None"
+	if diff := cmp.Diff(want, strings.Join(tokens, " ")); diff != "" {
+		t.Errorf("difference in result: (-want +got):\n%s", diff)
+	}
+}
+
+func TestRedactMalformedHint(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		input string
+		want  string
+	}{
+		{
+			desc:  "mismatched closing",
+			input: "SELECT * FROM t@{FORCE_INDEX=(idx]} WHERE ssn = '123-45-6789'",
+			want:  "SELECT * FROM t@{FORCE_INDEX=(idx]} WHERE ssn = '***'",
+		},
+		{
+			desc:  "literals in hints after malformed hint",
+			input: "SELECT * FROM t@{a=(1]} JOIN u@{b='secret'} ON x = 2",
+			want:  "SELECT * FROM t@{a=(0]} JOIN u@{b='***'} ON x = 0",
+		},
+		{
+			desc:  "unclosed hint before next statement",
+			input: "SELECT 1 FROM t@{a=1; SELECT 'secret'",
+			want:  "SELECT 0 FROM t@{a=0; SELECT '***'",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := gsqlutils.Redact("", tt.input)
+			if err != nil {
+				t.Fatalf("should success, but failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("difference in result: (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package tokenfilter
+
+import (
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/cloudspannerecosystem/memefish/token"
+)
+
+// RedactStyle returns the placeholder of a redacted token.
+// tok is a string, bytes, integer or float literal, or a query parameter if WithRedactParameterNames is given.
+type RedactStyle func(tok token.Token) string
+
+// RedactStyleQuestion replaces all redacted tokens with `?`.
+func RedactStyleQuestion(token.Token) string {
+	return "?"
+}
+
+// RedactStyleTyped replaces redacted tokens with placeholders of the same kinds, so the result is still lexically valid.
+// Strings become '***', bytes become b'***', integers become 0, floats become 0.0, and query parameters become @redacted.
+func RedactStyleTyped(tok token.Token) string {
+	switch tok.Kind {
+	case token.TokenString:
+		return "'***'"
+	case token.TokenBytes:
+		return "b'***'"
+	case token.TokenInt:
+		return "0"
+	case token.TokenFloat:
+		return "0.0"
+	case token.TokenParam:
+		return "@redacted"
+	default:
+		return tok.Raw
+	}
+}
+
+// RedactOption is an option of Redact.
+type RedactOption func(*redactor)
+
+// WithRedactStyle sets the style of placeholders. The default is RedactStyleTyped.
+func WithRedactStyle(style RedactStyle) RedactOption {
+	return func(r *redactor) {
+		r.style = style
+	}
+}
+
+// WithRedactParameterNames also redacts names of query parameters, for logs in which parameter names can be sensitive.
+// Values of query parameters are not a part of SQL text, so they never appear in the result.
+// All parameters are replaced with the same placeholder, so references to the same parameter can't be correlated.
+func WithRedactParameterNames() RedactOption {
+	return func(r *redactor) {
+		r.parameters = true
+	}
+}
+
+// WithRedactComments also blanks bodies of comments, which can contain sensitive values.
+// Comment markers like `--` and `/* */`, and newlines in comments are kept, so line numbers are not shifted.
+func WithRedactComments() RedactOption {
+	return func(r *redactor) {
+		r.comments = true
+	}
+}
+
+type redactor struct {
+	style      RedactStyle
+	parameters bool
+	comments   bool
+}
+
+func (r *redactor) target(tok token.Token) bool {
+	switch tok.Kind {
+	case token.TokenString, token.TokenBytes, token.TokenInt, token.TokenFloat:
+		return true
+	case token.TokenParam:
+		return r.parameters
+	default:
+		return false
+	}
+}
+
+// redact redacts tok, and its literal only if literal is true.
+func (r *redactor) redact(tok token.Token, literal bool) token.Token {
+	if literal && r.target(tok) {
+		tok.Raw = r.style(tok) + newlinesOf(tok.Raw)
+	}
+	if r.comments && len(tok.Comments) > 0 {
+		tok.Comments = slices.Clone(tok.Comments)
+		for i := range tok.Comments {
+			tok.Comments[i].Raw = blankComment(tok.Comments[i].Raw)
+		}
+	}
+	return tok
+}
+
+// blankComment removes the body of a comment except for newlines, e.g. `/* x */` becomes `/**/`.
+func blankComment(raw string) string {
+	switch {
+	case strings.HasPrefix(raw, "/*"):
+		return "/*" + newlinesOf(raw) + "*/"
+	case strings.HasPrefix(raw, "--"):
+		return "--" + newlinesOf(raw)
+	default:
+		return "#" + newlinesOf(raw)
+	}
+}
+
+func newlinesOf(s string) string {
+	return strings.Repeat("\n", strings.Count(s, "\n"))
+}
+
+// Redact replaces Raw of string, bytes and numeric literals with placeholders.
+// Identifiers, keywords and tokens in hints are not changed, and comments are not changed without WithRedactComments.
+// Positions of tokens are not changed.
+// It fails closed on malformed hints: literals in a malformed hint and in all following hints are also redacted.
+// If a redacted literal spans multiple lines, newlines are appended to the placeholder, so following lines keep their line numbers.
+func Redact(seq iter.Seq2[token.Token, error], opts ...RedactOption) iter.Seq2[token.Token, error] {
+	r := &redactor{style: RedactStyleTyped}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return func(yield func(token.Token, error) bool) {
+		var hints HintScanner
+
+		// Malformed hints are not errors of this filter, but hints are not trusted after them.
+		var malformed bool
+
+		// Tokens of the current hint are held until the hint ends, because it can turn out to be malformed.
+		var pending []token.Token
+		flush := func() bool {
+			for _, tok := range pending {
+				if !yield(r.redact(tok, malformed), nil) {
+					return false
+				}
+			}
+			pending = nil
+			return true
+		}
+
+		for tok, err := range seq {
+			if err != nil {
+				if flush() {
+					_ = yield(tok, err)
+				}
+				return
+			}
+
+			role, hintErr := hints.Next(tok)
+			malformed = malformed || hintErr != nil
+
+			switch role {
+			case HintRoleOpen, HintRoleBody:
+				pending = append(pending, tok)
+				continue
+			case HintRoleClose:
+				pending = append(pending, tok)
+				if !flush() {
+					return
+				}
+				continue
+			}
+
+			if !flush() || !yield(r.redact(tok, true), nil) {
+				return
+			}
+		}
+		_ = flush()
+	}
+}
@@ -24,6 +24,9 @@ var funcMap = map[string]func(string, string) (any, error){
 	"simple_strip_comments":                        wrapFunc(gsqlutils.SimpleStripComments),
 	"simple_skip_hints":                            wrapFunc(gsqlutils.SimpleSkipHints),
 	"separate_input_preserve_comments_with_status": wrapFunc(gsqlutils.SeparateInputPreserveCommentsWithStatus),
+	"format": wrapFunc(func(filepath, s string) (string, error) {
+		return gsqlutils.Format(filepath, s)
+	}),
 }
 
 func main() {